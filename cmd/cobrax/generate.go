@@ -0,0 +1,511 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+
+	"github.com/ZHLX2005/cobrax/cobra"
+)
+
+// cmdDir 生成的命令所在的目录（相对项目目录）
+const cmdDir = "cmd"
+
+// rootFunc 根命令的构造函数名
+const rootFunc = "newRootCmd"
+
+// commandNamePattern 合法的命令名称
+var commandNamePattern = regexp.MustCompile(`^[a-z][a-z0-9]*(-[a-z0-9]+)*$`)
+
+// fileChange 创建或修改的文件
+type fileChange struct {
+	action string // created, updated
+	path   string
+}
+
+// flagDef 命令行中 --flag 的定义
+type flagDef struct {
+	name      string
+	shorthand string
+	typ       string
+	value     string // 默认值的 Go 表达式
+}
+
+// flagTypes 支持的 flag 类型 -> 定义方法与读取方法的后缀
+var flagTypes = map[string]string{
+	"string":      "String",
+	"bool":        "Bool",
+	"int":         "Int",
+	"int64":       "Int64",
+	"float64":     "Float64",
+	"duration":    "Duration",
+	"stringSlice": "StringSlice",
+}
+
+// parseFlagDef 解析 name[,shorthand][:type][=default]
+func parseFlagDef(def string) (flagDef, error) {
+	spec, value, hasDefault := strings.Cut(def, "=")
+	names, typ, _ := strings.Cut(spec, ":")
+	name, shorthand, _ := strings.Cut(names, ",")
+
+	if typ == "" {
+		typ = "string"
+	}
+	if _, ok := flagTypes[typ]; !ok {
+		return flagDef{}, fmt.Errorf("unsupported type %q", typ)
+	}
+	if !commandNamePattern.MatchString(name) {
+		return flagDef{}, fmt.Errorf("flag name %q must be lowercase words joined by '-'", name)
+	}
+	if shorthand != "" && (len(shorthand) != 1 || !unicode.IsLetter(rune(shorthand[0])) || shorthand == "h") {
+		return flagDef{}, fmt.Errorf("shorthand %q must be a single letter other than h", shorthand)
+	}
+
+	literal, err := defaultLiteral(typ, value, hasDefault)
+	if err != nil {
+		return flagDef{}, err
+	}
+	return flagDef{name: name, shorthand: shorthand, typ: typ, value: literal}, nil
+}
+
+// defaultLiteral 将默认值转换为 Go 表达式
+func defaultLiteral(typ, value string, set bool) (string, error) {
+	switch typ {
+	case "string":
+		return strconv.Quote(value), nil
+	case "bool":
+		if !set {
+			return "false", nil
+		}
+		b, err := strconv.ParseBool(value)
+		return strconv.FormatBool(b), err
+	case "int", "int64":
+		if !set {
+			return "0", nil
+		}
+		n, err := strconv.ParseInt(value, 10, 64)
+		return strconv.FormatInt(n, 10), err
+	case "float64":
+		if !set {
+			return "0", nil
+		}
+		f, err := strconv.ParseFloat(value, 64)
+		return strconv.FormatFloat(f, 'g', -1, 64), err
+	case "duration":
+		if !set {
+			return "0", nil
+		}
+		d, err := time.ParseDuration(value)
+		return durationLiteral(d), err
+	default: // stringSlice
+		if !set || value == "" {
+			return "nil", nil
+		}
+		items := strings.Split(value, ",")
+		for i, item := range items {
+			items[i] = strconv.Quote(item)
+		}
+		return "[]string{" + strings.Join(items, ", ") + "}", nil
+	}
+}
+
+// durationLiteral 以最大的整数单位表示时长，如 30*time.Second
+func durationLiteral(d time.Duration) string {
+	units := []struct {
+		unit time.Duration
+		name string
+	}{
+		{time.Hour, "time.Hour"},
+		{time.Minute, "time.Minute"},
+		{time.Second, "time.Second"},
+		{time.Millisecond, "time.Millisecond"},
+	}
+	for _, u := range units {
+		if d != 0 && d%u.unit == 0 {
+			return fmt.Sprintf("%d * %s", d/u.unit, u.name)
+		}
+	}
+	return fmt.Sprintf("time.Duration(%d)", d)
+}
+
+// method 定义 flag 的方法（Bool 或 BoolP）
+func (f flagDef) method() string {
+	if f.shorthand != "" {
+		return flagTypes[f.typ] + "P"
+	}
+	return flagTypes[f.typ]
+}
+
+// args 定义 flag 的参数
+func (f flagDef) args() string {
+	usage := strconv.Quote("Help message for " + f.name)
+	if f.shorthand != "" {
+		return fmt.Sprintf("%q, %q, %s, %s", f.name, f.shorthand, f.value, usage)
+	}
+	return fmt.Sprintf("%q, %s, %s", f.name, f.value, usage)
+}
+
+// getter 读取 flag 的方法
+func (f flagDef) getter() string {
+	return "Get" + flagTypes[f.typ]
+}
+
+// variable 保存 flag 值的变量名，与关键字或生成代码中的名称冲突时加 Flag 后缀
+func (f flagDef) variable() string {
+	name := lowerFirst(camelCase(f.name))
+	if token.IsKeyword(name) || name == "cmd" || name == "args" || name == "flags" || name == "fmt" || name == "cobra" {
+		name += "Flag"
+	}
+	return name
+}
+
+// camelCase dry-run -> DryRun
+func camelCase(s string) string {
+	var builder strings.Builder
+	for _, part := range strings.Split(s, "-") {
+		if part != "" {
+			builder.WriteString(strings.ToUpper(part[:1]) + part[1:])
+		}
+	}
+	return builder.String()
+}
+
+// lowerFirst DryRun -> dryRun
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
+
+// commandFunc 命令路径对应的构造函数名：config/init -> newConfigInitCmd
+func commandFunc(names []string) string {
+	if len(names) == 0 {
+		return rootFunc
+	}
+	var builder strings.Builder
+	for _, name := range names {
+		builder.WriteString(camelCase(name))
+	}
+	return "new" + builder.String() + "Cmd"
+}
+
+// commandFile 命令路径对应的文件名：config/init -> config_init.go
+func commandFile(names []string) string {
+	return strings.ReplaceAll(strings.Join(names, "_"), "-", "_") + ".go"
+}
+
+var rootTemplate = template.Must(template.New("root").Parse(`package cmd
+
+import (
+	"github.com/ZHLX2005/cobrax/cobra"
+)
+
+// newRootCmd 创建根命令
+func newRootCmd() *cobra.Command {
+	cmd := cobra.NewCommand({{printf "%q" .Name}},
+		cobra.WithShort({{printf "%q" .Short}}),
+		cobra.WithVersion(""),
+	)
+	cmd.AddCommand()
+	return cmd
+}
+
+// Execute 执行根命令，按错误类型设置退出码
+func Execute() {
+	newRootCmd().ExecuteAndExit()
+}
+`))
+
+var mainTemplate = template.Must(template.New("main").Parse(`package main
+
+import "{{.Module}}/cmd"
+
+func main() {
+	cmd.Execute()
+}
+`))
+
+var commandTemplate = template.Must(template.New("command").Parse(`package cmd
+
+import (
+	"fmt"
+{{- if .Duration}}
+	"time"
+{{- end}}
+
+	"github.com/ZHLX2005/cobrax/cobra"
+)
+
+// {{.Func}} 创建 {{.Path}} 命令
+func {{.Func}}() *cobra.Command {
+	cmd := cobra.NewCommand({{printf "%q" .Name}},
+		cobra.WithShort({{printf "%q" .Short}}),
+{{- if .Flags}}
+		cobra.WithFlags(func(flags *cobra.FlagSet) {
+{{- range .Flags}}
+			flags.{{.Method}}({{.Args}})
+{{- end}}
+		}),
+{{- end}}
+		cobra.WithRunE(func(cmd *cobra.Command, args []string) error {
+{{- range .Flags}}
+			{{.Variable}}, _ := cmd.Flags().{{.Getter}}({{printf "%q" .Name}})
+{{- end}}
+{{- if .Flags}}
+			fmt.Fprintf(cmd.OutOrStdout(), {{printf "%q" .Format}}{{range .Flags}}, {{.Variable}}{{end}})
+{{- else}}
+			fmt.Fprintln(cmd.OutOrStdout(), {{printf "%q" .Format}})
+{{- end}}
+			return nil
+		}),
+	)
+	cmd.AddCommand()
+	return cmd
+}
+`))
+
+// flagData 模板中使用的 flag 数据
+type flagData struct {
+	Name, Method, Args, Getter, Variable string
+}
+
+// initProject 创建应用骨架
+func initProject(dir, module, name, short string) ([]fileChange, error) {
+	existing, err := readModulePath(dir)
+	switch {
+	case err != nil && !errors.Is(err, os.ErrNotExist):
+		return nil, err
+	case existing != "" && module != "" && existing != module:
+		return nil, cobra.NewUsageError("go.mod already declares module %q, not %q", existing, module)
+	case existing == "" && module == "":
+		return nil, cobra.NewUsageError("no go.mod found in %s", dir).
+			WithHint("pass the module path, e.g. cobrax init github.com/me/myapp")
+	case existing != "":
+		module = existing
+	}
+
+	if name == "" {
+		name = path.Base(module)
+	}
+	if !commandNamePattern.MatchString(name) {
+		return nil, cobra.NewUsageError("root command name %q must be lowercase words joined by '-'", name).
+			WithHint("set the name with --name")
+	}
+	if short == "" {
+		short = "A brief description of " + name
+	}
+
+	files := []struct {
+		path string
+		tmpl *template.Template
+	}{
+		{"main.go", mainTemplate},
+		{filepath.Join(cmdDir, "root.go"), rootTemplate},
+	}
+	for _, f := range files {
+		if _, err := os.Stat(filepath.Join(dir, f.path)); err == nil {
+			return nil, cobra.NewUsageError("%s already exists", filepath.Join(dir, f.path))
+		}
+	}
+
+	var changes []fileChange
+	if existing == "" {
+		content := fmt.Sprintf("module %s\n\ngo 1.24\n", module)
+		if err := writeNewFile(filepath.Join(dir, "go.mod"), []byte(content)); err != nil {
+			return nil, err
+		}
+		changes = append(changes, fileChange{"created", filepath.Join(dir, "go.mod")})
+	}
+
+	data := map[string]string{"Module": module, "Name": name, "Short": short}
+	for _, f := range files {
+		source, err := render(f.tmpl, data)
+		if err != nil {
+			return nil, err
+		}
+		target := filepath.Join(dir, f.path)
+		if err := writeNewFile(target, source); err != nil {
+			return nil, err
+		}
+		changes = append(changes, fileChange{"created", target})
+	}
+	return changes, nil
+}
+
+// addCommand 在 cmd/ 下生成命令文件，并将其注册到父命令
+func addCommand(dir, commandPath, short string, flags []flagDef) ([]fileChange, error) {
+	names := strings.Split(strings.Trim(commandPath, "/"), "/")
+	for _, name := range names {
+		if !commandNamePattern.MatchString(name) {
+			return nil, cobra.NewUsageError("invalid command name %q in %q", name, commandPath).
+				WithHint("use lowercase words joined by '-', separated by '/', e.g. config/init")
+		}
+	}
+	seen := make(map[string]bool)
+	for _, f := range flags {
+		if seen[f.name] || (f.shorthand != "" && seen["-"+f.shorthand]) {
+			return nil, cobra.NewUsageError("flag --%s is defined twice or reuses a shorthand", f.name)
+		}
+		seen[f.name], seen["-"+f.shorthand] = true, f.shorthand != ""
+	}
+
+	pkgDir := filepath.Join(dir, cmdDir)
+	fn := commandFunc(names)
+	parentFn := commandFunc(names[:len(names)-1])
+	target := filepath.Join(pkgDir, commandFile(names))
+
+	if _, err := os.Stat(target); err == nil {
+		return nil, cobra.NewUsageError("%s already exists", target)
+	}
+	pkg, err := parsePackage(pkgDir)
+	if err != nil {
+		return nil, err
+	}
+	if pkg.find(fn) != nil {
+		return nil, cobra.NewUsageError("command %q already exists (%s is declared)", strings.Join(names, " "), fn)
+	}
+	parent := pkg.find(parentFn)
+	if parent == nil {
+		hint := "run 'cobrax init' first"
+		if len(names) > 1 {
+			hint = fmt.Sprintf("add the parent first with 'cobrax add %s'", strings.Join(names[:len(names)-1], "/"))
+		}
+		return nil, cobra.NewNotFoundError("parent command function %s not found in %s", parentFn, pkgDir).WithHint(hint)
+	}
+
+	source, err := renderCommand(names, fn, short, flags)
+	if err != nil {
+		return nil, err
+	}
+	updated, err := registerChild(pkg.fset, parent, fn)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := writeNewFile(target, source); err != nil {
+		return nil, err
+	}
+	if err := replaceFile(parent.path, updated); err != nil {
+		_ = os.Remove(target)
+		return nil, err
+	}
+	return []fileChange{{"created", target}, {"updated", parent.path}}, nil
+}
+
+// renderCommand 生成命令文件
+func renderCommand(names []string, fn, short string, flags []flagDef) ([]byte, error) {
+	name := names[len(names)-1]
+	if short == "" {
+		short = "A brief description of " + name
+	}
+
+	data := struct {
+		Func, Name, Path, Short, Format string
+		Duration                        bool
+		Flags                           []flagData
+	}{
+		Func:  fn,
+		Name:  name,
+		Path:  strings.Join(names, " "),
+		Short: short,
+	}
+
+	values := make([]string, 0, len(flags))
+	for _, f := range flags {
+		data.Duration = data.Duration || (f.typ == "duration" && f.value != "0")
+		data.Flags = append(data.Flags, flagData{
+			Name:     f.name,
+			Method:   f.method(),
+			Args:     f.args(),
+			Getter:   f.getter(),
+			Variable: f.variable(),
+		})
+		values = append(values, f.name+": %v")
+	}
+	data.Format = data.Path + " called"
+	if len(values) > 0 {
+		data.Format += " (" + strings.Join(values, ", ") + ")\n"
+	}
+
+	return render(commandTemplate, data)
+}
+
+// render 执行模板并格式化代码
+func render(tmpl *template.Template, data interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	source, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("generated code does not compile: %w", err)
+	}
+	return source, nil
+}
+
+// readModulePath 读取 go.mod 中的模块路径
+func readModulePath(dir string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return "", err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if rest, ok := strings.CutPrefix(strings.TrimSpace(line), "module "); ok {
+			return strings.Trim(strings.TrimSpace(rest), `"`), nil
+		}
+	}
+	return "", fmt.Errorf("%s: missing module directive", filepath.Join(dir, "go.mod"))
+}
+
+// writeNewFile 创建文件（必要时创建目录），文件已存在时失败
+func writeNewFile(name string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// replaceFile 通过临时文件原子地替换文件内容
+func replaceFile(name string, data []byte) error {
+	info, err := os.Stat(name)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(name), ".cobrax-*.go")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), info.Mode().Perm()); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), name)
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"errors"
+	"go/ast"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ZHLX2005/cobrax/cobra"
+)
+
+func TestInitAndAddCommand(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := initProject(dir, "example.com/demo", "", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	var flags []flagDef
+	for _, def := range []string{"force,f:bool", "timeout:duration=30s", "type"} {
+		f, err := parseFlagDef(def)
+		if err != nil {
+			t.Fatalf("parseFlagDef(%q) = %v", def, err)
+		}
+		flags = append(flags, f)
+	}
+	if _, err := addCommand(dir, "config", "Manage configuration", nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := addCommand(dir, "config/init", "", flags); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := addCommand(dir, "version-info", "", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// 所有生成和改写的文件都能解析，父命令的 AddCommand 调用包含新的子命令
+	pkg, err := parsePackage(filepath.Join(dir, cmdDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for parent, want := range map[string][]string{
+		"newRootCmd":       {"newConfigCmd", "newVersionInfoCmd"},
+		"newConfigCmd":     {"newConfigInitCmd"},
+		"newConfigInitCmd": nil,
+	} {
+		decl := pkg.find(parent)
+		if decl == nil {
+			t.Fatalf("%s not declared", parent)
+		}
+		var got []string
+		if call := findAddCommand(decl.decl.Body, "cmd"); call != nil {
+			for _, arg := range call.Args {
+				got = append(got, arg.(*ast.CallExpr).Fun.(*ast.Ident).Name)
+			}
+		}
+		if strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Errorf("%s registers %v, want %v", parent, got, want)
+		}
+	}
+
+	source, err := os.ReadFile(filepath.Join(dir, cmdDir, "config_init.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		`flags.BoolP("force", "f", false, "Help message for force")`,
+		`flags.Duration("timeout", 30*time.Second, "Help message for timeout")`,
+		`typeFlag, _ := cmd.Flags().GetString("type")`,
+		`fmt.Fprintf(cmd.OutOrStdout(), `,
+	} {
+		if !strings.Contains(string(source), s) {
+			t.Errorf("config_init.go missing %q:\n%s", s, source)
+		}
+	}
+
+	// 命令已存在或父命令不存在时失败
+	if _, err := addCommand(dir, "config", "", nil); cobra.ExitCode(err) != cobra.ExitCodeUsage {
+		t.Errorf("adding an existing command = %v, want a usage error", err)
+	}
+	if _, err := addCommand(dir, "missing/child", "", nil); cobra.ExitCode(err) != cobra.ExitCodeNotFound {
+		t.Errorf("adding under a missing parent = %v, want a not-found error", err)
+	}
+}
+
+func TestRegisterChildUnsupportedParent(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		reason string
+	}{
+		{"no body", "func newRootCmd() *cobra.Command", "has no body"},
+		{"ends with panic", "func newRootCmd() *cobra.Command {\n\tpanic(\"todo\")\n}", "does not end with a return statement"},
+		{"bare return", "func newRootCmd() (cmd *cobra.Command) {\n\tcmd = build()\n\tif cmd == nil {\n\t\treturn\n\t}\n\tcmd.Use = \"app\"\n\treturn\n}", "does not end with a return statement"},
+		{"returns a call", "func newRootCmd() *cobra.Command {\n\treturn cobra.NewCommand(\"app\")\n}", "does not return a variable"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			source := "package cmd\n\nimport \"github.com/ZHLX2005/cobrax/cobra\"\n\n" + tt.body + "\n"
+			if err := os.WriteFile(filepath.Join(dir, "root.go"), []byte(source), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			pkg, err := parsePackage(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = registerChild(pkg.fset, pkg.find(rootFunc), "newServeCmd")
+			var e *cobra.Error
+			if !errors.As(err, &e) || e.Category != cobra.CategoryUsage {
+				t.Fatalf("registerChild() = %#v, want a usage *cobra.Error", err)
+			}
+			if !strings.Contains(e.Message, tt.reason) {
+				t.Errorf("message = %q, want it to contain %q", e.Message, tt.reason)
+			}
+		})
+	}
+}
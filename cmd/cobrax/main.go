@@ -0,0 +1,104 @@
+// cobrax 是 cobrax 命令行应用的脚手架工具
+//
+//	cobrax init github.com/me/myapp
+//	cobrax add config
+//	cobrax add config/init --flag force,f:bool --short "Initialize configuration"
+package main
+
+import (
+	"fmt"
+
+	"github.com/ZHLX2005/cobrax/cobra"
+)
+
+func main() {
+	rootCmd := cobra.NewCommand("cobrax",
+		cobra.WithShort("Scaffold cobrax applications"),
+		cobra.WithLong("cobrax creates application skeletons and adds commands in the NewCommand/WithRunE style."),
+		cobra.WithVersion(""),
+		cobra.WithSilenceUsage(),
+		cobra.WithPersistentFlags(func(flags *cobra.FlagSet) {
+			flags.StringP("dir", "d", ".", "Project directory")
+		}),
+		cobra.WithSubcommands(newInitCmd(), newAddCmd()),
+	)
+	rootCmd.ExecuteAndExit()
+}
+
+// newInitCmd 创建 init 命令
+func newInitCmd() *cobra.Command {
+	return cobra.NewCommand("init",
+		cobra.WithShort("Create a new application skeleton"),
+		cobra.WithLong("Create go.mod (unless present), main.go and cmd/root.go in the project directory.\n"+
+			"The module path may be omitted when the directory already has a go.mod."),
+		cobra.WithExample("  cobrax init github.com/me/myapp --dir myapp"),
+		cobra.WithArgs(cobra.MaximumNArgs(1)),
+		cobra.WithFlags(func(flags *cobra.FlagSet) {
+			flags.StringP("name", "n", "", "Root command name (default: last element of the module path)")
+			flags.StringP("short", "s", "", "Short description of the root command")
+		}),
+		cobra.WithRunE(func(cmd *cobra.Command, args []string) error {
+			dir, _ := cmd.Flags().GetString("dir")
+			name, _ := cmd.Flags().GetString("name")
+			short, _ := cmd.Flags().GetString("short")
+
+			module := ""
+			if len(args) > 0 {
+				module = args[0]
+			}
+			files, err := initProject(dir, module, name, short)
+			if err != nil {
+				return err
+			}
+			reportFiles(cmd, files)
+			fmt.Fprintln(cmd.OutOrStdout(), "Run 'go mod tidy' to fetch dependencies.")
+			return nil
+		}),
+	)
+}
+
+// newAddCmd 创建 add 命令
+func newAddCmd() *cobra.Command {
+	return cobra.NewCommand("add",
+		cobra.WithShort("Add a command to an application"),
+		cobra.WithLong("Add a command at a slash-separated path below the root command.\n"+
+			"The command is written to its own file in cmd/ and registered in its parent's AddCommand call.\n"+
+			"Flags are given as name[,shorthand][:type][=default]; types are string (default), bool, int,\n"+
+			"int64, float64, duration and stringSlice."),
+		cobra.WithExample("  cobrax add config\n  cobrax add config/init --flag force,f:bool --flag retries:int=3"),
+		cobra.WithArgs(cobra.ExactArgs(1)),
+		cobra.WithFlags(func(flags *cobra.FlagSet) {
+			flags.StringArrayP("flag", "f", nil, "Flag definition as name[,shorthand][:type][=default] (repeatable)")
+			flags.StringP("short", "s", "", "Short description of the command")
+		}),
+		cobra.WithRunE(func(cmd *cobra.Command, args []string) error {
+			dir, _ := cmd.Flags().GetString("dir")
+			defs, _ := cmd.Flags().GetStringArray("flag")
+			short, _ := cmd.Flags().GetString("short")
+
+			flags := make([]flagDef, 0, len(defs))
+			for _, def := range defs {
+				flag, err := parseFlagDef(def)
+				if err != nil {
+					return cobra.NewUsageError("invalid --flag %q: %v", def, err).
+						WithHint("use name[,shorthand][:type][=default], e.g. force,f:bool")
+				}
+				flags = append(flags, flag)
+			}
+
+			files, err := addCommand(dir, args[0], short, flags)
+			if err != nil {
+				return err
+			}
+			reportFiles(cmd, files)
+			return nil
+		}),
+	)
+}
+
+// reportFiles 输出创建或修改的文件
+func reportFiles(cmd *cobra.Command, files []fileChange) {
+	for _, f := range files {
+		fmt.Fprintf(cmd.OutOrStdout(), "%-8s %s\n", f.action, f.path)
+	}
+}
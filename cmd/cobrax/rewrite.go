@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strings"
+
+	"github.com/ZHLX2005/cobrax/cobra"
+)
+
+// goPackage 解析后的命令包
+type goPackage struct {
+	fset  *token.FileSet
+	funcs map[string]*funcDecl
+}
+
+// funcDecl 包中的函数声明及其所在文件
+type funcDecl struct {
+	decl *ast.FuncDecl
+	file *ast.File
+	path string
+}
+
+// find 按名称查找顶层函数
+func (p *goPackage) find(name string) *funcDecl {
+	return p.funcs[name]
+}
+
+// parsePackage 解析目录下的 Go 文件（不含测试文件）
+func parsePackage(dir string) (*goPackage, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+
+	pkg := &goPackage{fset: token.NewFileSet(), funcs: make(map[string]*funcDecl)}
+	for _, path := range paths {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(pkg.fset, path, nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		for _, decl := range file.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil {
+				pkg.funcs[fn.Name.Name] = &funcDecl{decl: fn, file: file, path: path}
+			}
+		}
+	}
+	return pkg, nil
+}
+
+// registerChild 在父命令的构造函数中注册子命令，返回修改后的文件内容
+//
+// 父命令的构造函数必须返回一个变量（如 return cmd）：已有 cmd.AddCommand(...) 调用时追加参数，
+// 否则在 return 之前插入 cmd.AddCommand(child())。修改后的代码会重新解析，确保仍然合法。
+func registerChild(fset *token.FileSet, parent *funcDecl, child string) ([]byte, error) {
+	body := parent.decl.Body
+	if body == nil || len(body.List) == 0 {
+		return nil, unsupportedParent(parent, "has no body")
+	}
+	ret, ok := body.List[len(body.List)-1].(*ast.ReturnStmt)
+	if !ok || len(ret.Results) != 1 {
+		return nil, unsupportedParent(parent, "does not end with a return statement")
+	}
+	variable, ok := ret.Results[0].(*ast.Ident)
+	if !ok {
+		return nil, unsupportedParent(parent, "does not return a variable")
+	}
+
+	// 新节点使用插入点的位置，使注释仍然保留在原来的语句之前
+	if call := findAddCommand(body, variable.Name); call != nil {
+		call.Args = append(call.Args, newCall(call.Rparen, child))
+	} else {
+		pos := ret.Pos()
+		addCommand := newCall(pos, "AddCommand", newCall(pos, child))
+		addCommand.Fun = &ast.SelectorExpr{X: &ast.Ident{NamePos: pos, Name: variable.Name}, Sel: addCommand.Fun.(*ast.Ident)}
+		body.List = append(body.List[:len(body.List)-1], &ast.ExprStmt{X: addCommand}, ret)
+	}
+
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, parent.file); err != nil {
+		return nil, fmt.Errorf("rewrite %s: %w", parent.path, err)
+	}
+	if _, err := parser.ParseFile(token.NewFileSet(), parent.path, buf.Bytes(), parser.ParseComments); err != nil {
+		return nil, fmt.Errorf("rewrite %s produced invalid code: %w", parent.path, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// newCall 在 pos 处创建函数调用 name(args...)
+func newCall(pos token.Pos, name string, args ...ast.Expr) *ast.CallExpr {
+	return &ast.CallExpr{
+		Fun:    &ast.Ident{NamePos: pos, Name: name},
+		Lparen: pos,
+		Args:   args,
+		Rparen: pos,
+	}
+}
+
+// findAddCommand 查找函数体中顶层的 <variable>.AddCommand(...) 调用
+func findAddCommand(body *ast.BlockStmt, variable string) *ast.CallExpr {
+	for _, stmt := range body.List {
+		expr, ok := stmt.(*ast.ExprStmt)
+		if !ok {
+			continue
+		}
+		call, ok := expr.X.(*ast.CallExpr)
+		if !ok || call.Ellipsis.IsValid() {
+			continue
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok || sel.Sel.Name != "AddCommand" {
+			continue
+		}
+		if recv, ok := sel.X.(*ast.Ident); ok && recv.Name == variable {
+			return call
+		}
+	}
+	return nil
+}
+
+// unsupportedParent 父命令构造函数的结构无法安全修改
+func unsupportedParent(parent *funcDecl, reason string) error {
+	return cobra.NewUsageError("cannot register the command in %s: %s %s", parent.path, parent.decl.Name.Name, reason).
+		WithHint(fmt.Sprintf("end %s with 'return cmd' and register children with cmd.AddCommand(...)", parent.decl.Name.Name))
+}
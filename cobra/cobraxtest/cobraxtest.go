@@ -0,0 +1,208 @@
+// Package cobraxtest 提供在进程内执行 cobrax 命令的测试工具
+//
+// Run 捕获标准输出、标准错误、退出码和返回的错误，执行期间隔离环境变量和工作目录：
+//
+//	func TestServer(t *testing.T) {
+//	    res := cobraxtest.Run(newRootCmd(), "server", "--port", "9090")
+//	    if res.ExitCode != 0 {
+//	        t.Fatalf("exit code %d: %s", res.ExitCode, res.Stderr)
+//	    }
+//	}
+//
+// 命令执行后会保留已解析的 flag 取值，每次 Run 都应传入新构建的根命令。
+// Run 会修改进程级的状态（环境变量、工作目录、os.Stdout 等），不能在 t.Parallel 的测试中使用。
+package cobraxtest
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/ZHLX2005/cobrax/cobra"
+)
+
+// Result 一次执行的结果
+type Result struct {
+	Stdout   string
+	Stderr   string
+	ExitCode int   // 与 ExecuteAndExit 使用的退出码一致
+	Err      error // Execute 返回的错误
+}
+
+// Runner 执行命令的设置
+//
+// 执行期间环境变量被清空，HOME 和用户配置、缓存目录指向临时目录，并关闭更新检查
+// （COBRA_NO_UPDATE_CHECK=true），Env 中的值在此之后设置，可以覆盖这些默认值。
+type Runner struct {
+	Env   map[string]string // 执行期间的环境变量
+	Dir   string            // 工作目录，为空时使用临时目录
+	Stdin io.Reader         // 标准输入，为空时为空输入
+}
+
+// mu 串行化执行，Run 会修改进程级的状态
+var mu sync.Mutex
+
+// Run 使用默认设置执行命令
+func Run(root *cobra.Command, args ...string) Result {
+	return Runner{}.Run(root, args...)
+}
+
+// Run 在隔离的环境中执行命令
+func (r Runner) Run(root *cobra.Command, args ...string) Result {
+	mu.Lock()
+	defer mu.Unlock()
+
+	restore, err := r.isolate()
+	if err != nil {
+		return Result{ExitCode: cobra.ExitCode(err), Err: err}
+	}
+	defer restore()
+
+	stdout, err := capture(&os.Stdout)
+	if err != nil {
+		return Result{ExitCode: cobra.ExitCode(err), Err: err}
+	}
+	stderr, err := capture(&os.Stderr)
+	if err != nil {
+		stdout.finish()
+		return Result{ExitCode: cobra.ExitCode(err), Err: err}
+	}
+	stdin, err := feed(r.Stdin)
+	if err != nil {
+		stdout.finish()
+		stderr.finish()
+		return Result{ExitCode: cobra.ExitCode(err), Err: err}
+	}
+	defer stdin()
+
+	root.SetOut(os.Stdout)
+	root.SetErr(os.Stderr)
+	root.SetIn(os.Stdin)
+	// 传入非 nil 的参数，避免回退到测试进程的 os.Args
+	root.SetArgs(append([]string{}, args...))
+
+	err = root.ExecuteContext(context.Background())
+	return Result{
+		Stdout:   stdout.finish(),
+		Stderr:   stderr.finish(),
+		ExitCode: cobra.ExitCode(err),
+		Err:      err,
+	}
+}
+
+// isolate 设置隔离的环境变量和工作目录，返回恢复函数
+func (r Runner) isolate() (func(), error) {
+	tmp, err := os.MkdirTemp("", "cobraxtest-")
+	if err != nil {
+		return nil, err
+	}
+	home, work := filepath.Join(tmp, "home"), filepath.Join(tmp, "work")
+	for _, dir := range []string{home, work} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			os.RemoveAll(tmp)
+			return nil, err
+		}
+	}
+
+	dir := r.Dir
+	if dir == "" {
+		dir = work
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		os.RemoveAll(tmp)
+		return nil, err
+	}
+	if err := os.Chdir(dir); err != nil {
+		os.RemoveAll(tmp)
+		return nil, err
+	}
+
+	environ := os.Environ()
+	os.Clearenv()
+	defaults := map[string]string{
+		"HOME":                  home,
+		"USERPROFILE":           home,
+		"XDG_CONFIG_HOME":       filepath.Join(home, ".config"),
+		"XDG_CACHE_HOME":        filepath.Join(home, ".cache"),
+		"APPDATA":               filepath.Join(home, "AppData", "Roaming"),
+		"LOCALAPPDATA":          filepath.Join(home, "AppData", "Local"),
+		"COBRA_NO_UPDATE_CHECK": "true",
+	}
+	for key, value := range defaults {
+		os.Setenv(key, value)
+	}
+	for key, value := range r.Env {
+		os.Setenv(key, value)
+	}
+
+	return func() {
+		os.Clearenv()
+		for _, kv := range environ {
+			if key, value, ok := strings.Cut(kv, "="); ok && key != "" {
+				os.Setenv(key, value)
+			}
+		}
+		_ = os.Chdir(wd)
+		os.RemoveAll(tmp)
+	}, nil
+}
+
+// capturedOutput 被捕获的输出
+type capturedOutput struct {
+	target   **os.File
+	original *os.File
+	writer   *os.File
+	buf      bytes.Buffer
+	done     chan struct{}
+}
+
+// capture 将 *target（os.Stdout 或 os.Stderr）替换为管道并在后台读取
+func capture(target **os.File) (*capturedOutput, error) {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+
+	c := &capturedOutput{target: target, original: *target, writer: writer, done: make(chan struct{})}
+	go func() {
+		defer close(c.done)
+		_, _ = io.Copy(&c.buf, reader)
+		reader.Close()
+	}()
+	*target = writer
+	return c, nil
+}
+
+// finish 恢复原来的文件并返回捕获的内容
+func (c *capturedOutput) finish() string {
+	*c.target = c.original
+	c.writer.Close()
+	<-c.done
+	return c.buf.String()
+}
+
+// feed 将 os.Stdin 替换为写入 input 的管道，返回恢复函数
+func feed(input io.Reader) (func(), error) {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		if input != nil {
+			_, _ = io.Copy(writer, input)
+		}
+		writer.Close()
+	}()
+
+	original := os.Stdin
+	os.Stdin = reader
+	return func() {
+		os.Stdin = original
+		reader.Close()
+	}, nil
+}
@@ -0,0 +1,121 @@
+package cobraxtest
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ZHLX2005/cobrax/cobra"
+)
+
+// newTestRoot 构建测试使用的命令树
+func newTestRoot() *cobra.Command {
+	return cobra.NewCommand("app",
+		cobra.WithShort("Test application"),
+		cobra.WithEnvPrefix("APP"),
+		cobra.WithSubcommands(
+			cobra.NewCommand("greet",
+				cobra.WithShort("Print a greeting"),
+				cobra.WithFlags(func(flags *cobra.FlagSet) {
+					flags.StringP("name", "n", "world", "Name to greet")
+				}),
+				cobra.WithRunE(func(cmd *cobra.Command, args []string) error {
+					name, _ := cmd.Flags().GetString("name")
+					fmt.Printf("hello %s\n", name)
+					fmt.Fprintln(cmd.ErrOrStderr(), "greeted")
+					return nil
+				}),
+			),
+			cobra.NewCommand("env",
+				cobra.WithShort("Print the environment and working directory"),
+				cobra.WithRunE(func(cmd *cobra.Command, args []string) error {
+					wd, _ := os.Getwd()
+					fmt.Fprintf(cmd.OutOrStdout(), "outer=%s wd=%s\n", os.Getenv("COBRAXTEST_OUTER"), wd)
+					return nil
+				}),
+			),
+			cobra.NewCommand("echo",
+				cobra.WithShort("Copy stdin to stdout"),
+				cobra.WithRunE(func(cmd *cobra.Command, args []string) error {
+					_, err := io.Copy(cmd.OutOrStdout(), cmd.InOrStdin())
+					return err
+				}),
+			),
+			cobra.NewCommand("find",
+				cobra.WithShort("Fail with a not-found error"),
+				cobra.WithRunE(func(cmd *cobra.Command, args []string) error {
+					return cobra.NewNotFoundError("item %q does not exist", "x")
+				}),
+			),
+		),
+	)
+}
+
+func TestRunCapturesOutput(t *testing.T) {
+	res := Runner{Env: map[string]string{"APP_NAME": "env"}}.Run(newTestRoot(), "greet")
+	if res.ExitCode != 0 || res.Err != nil {
+		t.Fatalf("exit code %d, err %v", res.ExitCode, res.Err)
+	}
+	if res.Stdout != "hello env\n" {
+		t.Errorf("stdout = %q, want %q", res.Stdout, "hello env\n")
+	}
+	if res.Stderr != "greeted\n" {
+		t.Errorf("stderr = %q, want %q", res.Stderr, "greeted\n")
+	}
+}
+
+func TestRunExitCode(t *testing.T) {
+	res := Run(newTestRoot(), "find")
+	if res.ExitCode != cobra.ExitCodeNotFound {
+		t.Errorf("exit code = %d, want %d", res.ExitCode, cobra.ExitCodeNotFound)
+	}
+	if !strings.Contains(res.Stderr, `item "x" does not exist`) {
+		t.Errorf("stderr = %q, want the error message", res.Stderr)
+	}
+
+	res = Run(newTestRoot(), "unknown")
+	if res.ExitCode != cobra.ExitCodeUsage {
+		t.Errorf("unknown command: exit code = %d, want %d", res.ExitCode, cobra.ExitCodeUsage)
+	}
+}
+
+func TestRunIsolatesEnvironment(t *testing.T) {
+	t.Setenv("COBRAXTEST_OUTER", "leaked")
+	wd, _ := os.Getwd()
+
+	dir := t.TempDir()
+	res := Runner{Dir: dir}.Run(newTestRoot(), "env")
+	if want := "outer= wd=" + dir + "\n"; res.Stdout != want {
+		t.Errorf("stdout = %q, want %q", res.Stdout, want)
+	}
+
+	if got := os.Getenv("COBRAXTEST_OUTER"); got != "leaked" {
+		t.Errorf("environment not restored: COBRAXTEST_OUTER = %q", got)
+	}
+	if got, _ := os.Getwd(); got != wd {
+		t.Errorf("working directory not restored: %s", got)
+	}
+}
+
+func TestRunStdin(t *testing.T) {
+	res := Runner{Stdin: strings.NewReader("line 1\nline 2\n")}.Run(newTestRoot(), "echo")
+	if res.Stdout != "line 1\nline 2\n" {
+		t.Errorf("stdout = %q", res.Stdout)
+	}
+
+	// 未设置 Stdin 时为空输入，不会阻塞
+	if res := Run(newTestRoot(), "echo"); res.Stdout != "" || res.Err != nil {
+		t.Errorf("empty stdin: stdout = %q, err = %v", res.Stdout, res.Err)
+	}
+}
+
+func TestTreeGolden(t *testing.T) {
+	AssertTreeGolden(t, newTestRoot(), "tree")
+	AssertTreeGolden(t, newTestRoot(), "tree-flags", "--tree-flags")
+}
+
+func TestHelpGolden(t *testing.T) {
+	AssertHelpGolden(t, newTestRoot(), "help-greet", "greet")
+}
@@ -0,0 +1,101 @@
+package cobraxtest
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ZHLX2005/cobrax/cobra"
+)
+
+// updateGoldenEnv 设置为 true 时重新生成 golden 文件
+const updateGoldenEnv = "COBRAX_UPDATE_GOLDEN"
+
+// updateGolden 判断是否重新生成 golden 文件：COBRAX_UPDATE_GOLDEN=true，
+// 或测试包自行定义并设置了 -update flag（本包不注册 flag，避免与使用方的同名 flag 冲突）
+func updateGolden() bool {
+	if update, err := strconv.ParseBool(os.Getenv(updateGoldenEnv)); err == nil {
+		return update
+	}
+	if f := flag.Lookup("update"); f != nil {
+		update, _ := strconv.ParseBool(f.Value.String())
+		return update
+	}
+	return false
+}
+
+// GoldenPath 返回 golden 文件路径 testdata/<name>.golden
+func GoldenPath(name string) string {
+	return filepath.Join("testdata", name+".golden")
+}
+
+// AssertGolden 比较 got 与 golden 文件的内容，COBRAX_UPDATE_GOLDEN=true 或 -update 时改为写入 got
+func AssertGolden(t testing.TB, name, got string) {
+	t.Helper()
+
+	path := GoldenPath(name)
+	if updateGolden() {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read golden file: %v (run with COBRAX_UPDATE_GOLDEN=true to create it)", err)
+	}
+	if got != string(want) {
+		t.Errorf("output does not match %s (run with COBRAX_UPDATE_GOLDEN=true to accept):\n%s", path, diffLines(string(want), got))
+	}
+}
+
+// AssertTreeGolden 以 --tree 执行 args 并将标准输出与 golden 文件比较
+func AssertTreeGolden(t testing.TB, root *cobra.Command, name string, args ...string) {
+	t.Helper()
+	assertOutputGolden(t, root, name, append(args, "--tree"))
+}
+
+// AssertHelpGolden 以 --help 执行 args 并将标准输出与 golden 文件比较
+func AssertHelpGolden(t testing.TB, root *cobra.Command, name string, args ...string) {
+	t.Helper()
+	assertOutputGolden(t, root, name, append(args, "--help"))
+}
+
+// assertOutputGolden 执行命令，要求成功后比较标准输出
+func assertOutputGolden(t testing.TB, root *cobra.Command, name string, args []string) {
+	t.Helper()
+
+	res := Run(root, args...)
+	if res.ExitCode != 0 {
+		t.Fatalf("%s: exit code %d: %v\n%s", strings.Join(args, " "), res.ExitCode, res.Err, res.Stderr)
+	}
+	AssertGolden(t, name, res.Stdout)
+}
+
+// diffLines 逐行列出期望与实际输出的差异
+func diffLines(want, got string) string {
+	wantLines, gotLines := strings.Split(want, "\n"), strings.Split(got, "\n")
+
+	var builder strings.Builder
+	for i := 0; i < max(len(wantLines), len(gotLines)); i++ {
+		var w, g string
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if i >= len(wantLines) || i >= len(gotLines) || w != g {
+			fmt.Fprintf(&builder, "  line %d:\n    - %q\n    + %q\n", i+1, w, g)
+		}
+	}
+	return builder.String()
+}
@@ -1,8 +1,10 @@
 package cobra
 
 import (
-	"fmt"
+	"context"
+	"errors"
 	"os"
+	"strings"
 
 	spf13cobra "github.com/spf13/cobra"
 )
@@ -14,6 +16,67 @@ type Command struct {
 
 	// treeConfig 树形展示配置
 	treeConfig *TreeConfig
+
+	// themeSet 是否通过 WithTreeTheme/SetTreeTheme 显式设置了主题
+	themeSet bool
+
+	// envPrefix 环境变量前缀，为空表示不启用环境变量绑定
+	envPrefix string
+
+	// config 配置文件设置，为 nil 表示不启用配置文件
+	config *configSettings
+
+	// profiles profile 设置，为 nil 表示不启用 profile
+	profiles *profileSettings
+
+	// middlewares 通过 Use 注册的中间件
+	middlewares []Middleware
+
+	// helpInstalled 是否已安装处理 --tree 的帮助函数
+	helpInstalled bool
+
+	// flagErrorsTyped 是否已包装 FlagErrorFunc
+	flagErrorsTyped bool
+
+	// helpErr 帮助函数中显示命令树失败的错误（已输出），由 Execute 作为返回值
+	helpErr error
+
+	// timeout 是否启用 --timeout flag
+	timeout bool
+
+	// cancelTimeout 释放 --timeout 创建的 context
+	cancelTimeout func()
+	// version 版本命令设置
+	version *versionSettings
+	// snapshot 命令树快照设置
+	snapshot *snapshotSettings
+	// plugins 外部插件设置
+	plugins *pluginSettings
+	// update 更新检查设置
+	update *updateSettings
+	// args 通过 SetArgs 设置的执行参数
+	args []string
+	// recovery panic 恢复设置
+	recovery *recoverySettings
+	// i18n 多语言设置，为 nil 表示不启用
+	i18n *i18nSettings
+	// output 结构化输出设置，为 nil 表示未启用 --output 等 flags
+	output *outputSettings
+	// current/currentArgs 正在执行的命令及其（脱敏后的）参数，用于崩溃报告
+	current     *spf13cobra.Command
+	currentArgs []string
+	// parent 最近的 cobrax 父命令（通过 AddCommand 添加时设置），根命令为 nil
+	parent *Command
+	// subcommands 通过 cobrax 添加的子命令
+	subcommands map[*spf13cobra.Command]*Command
+	// lazy 通过 AddLazyCommand 注册、尚未构建的占位子命令
+	lazy map[*spf13cobra.Command]*lazyCommand
+	// registered 子命令的注册顺序
+	registered []*spf13cobra.Command
+	// wrappedRuns 已经包装过中间件链的命令（只在根命令上记录）
+	wrappedRuns map[*spf13cobra.Command]bool
+	// hooks 被接管的用户持久化钩子（只在根命令上记录）
+	hooks hookRegistry
 }
 
 // NewCommand 创建一个新的命令
@@ -76,11 +139,15 @@ func WithRunE(fn func(*Command, []string) error) CommandOption {
 	}
 }
 
-// wrapCommand 包装 spf13cobra.Command 为 cobra.Command
+// wrapCommand 包装 spf13cobra.Command 为 cobra.Command，cmd 为 c 自身时直接返回 c
 func (c *Command) wrapCommand(cmd *spf13cobra.Command) *Command {
+	if cmd == c.Command {
+		return c
+	}
 	return &Command{
 		Command:    cmd,
 		treeConfig: c.treeConfig,
+		parent:     c,
 	}
 }
 
@@ -88,51 +155,232 @@ func (c *Command) wrapCommand(cmd *spf13cobra.Command) *Command {
 func WithTreeTheme(theme *TreeTheme) CommandOption {
 	return func(c *Command) {
 		c.treeConfig = &TreeConfig{Theme: theme}
+		c.themeSet = true
 	}
 }
 
 // initTreeFlags 初始化 tree 相关的 flags
 func (c *Command) initTreeFlags() {
 	// 添加 tree flags 到主命令的 flag set
-	c.Flags().Bool("tree", false, "Display command tree")
-	c.Flags().String("tree-theme", "default", "Tree theme (default, dracula, nord, monokai, light)")
-	c.Flags().Bool("tree-flags", false, "Show flags in tree view")
-	c.Flags().Bool("tree-long", true, "Show long descriptions in tree view")
+	c.Flags().Bool("tree", false, defaultMessages[MsgFlagTree])
+	c.Flags().String("tree-theme", "default", defaultMessages[MsgFlagTreeTheme])
+	c.Flags().Bool("tree-flags", false, defaultMessages[MsgFlagTreeFlags])
+	c.Flags().Bool("tree-long", true, defaultMessages[MsgFlagTreeLong])
+	c.Flags().String("tree-sort", string(TreeSortGroup), defaultMessages[MsgFlagTreeSort])
+	c.Flags().String("tree-format", string(TreeFormatText), defaultMessages[MsgFlagTreeFormat])
+	c.Flags().Int("tree-width", 0, defaultMessages[MsgFlagTreeWidth])
+	c.Flags().Bool(noPagerFlagName, false, defaultMessages[MsgFlagNoPager])
+}
+
+// isBuiltinFlag 判断是否为 cobrax 内置的 flag
+func isBuiltinFlag(name string) bool {
+	return name == "tree" || strings.HasPrefix(name, "tree-") || name == noPagerFlagName
 }
 
 // Execute 执行命令
 func (c *Command) Execute() error {
-	// 保存原始的帮助函数
-	oldHelpFunc := c.HelpFunc()
-
-	// 设置新的帮助函数来检查 --tree flag
-	c.SetHelpFunc(func(command *spf13cobra.Command, strs []string) {
-		// 检查是否显示树形视图
-		if c.shouldShowTree() {
-			c.showTree()
-			os.Exit(0)
+	return c.execute(nil)
+}
+
+// execute 执行命令的公共流程，ctx 为 nil 时沿用命令已有的 context
+func (c *Command) execute(ctx context.Context) error {
+	// 补全请求优先使用命令树快照
+	args := c.executeArgs()
+	c.resolveLocale(args)
+	if c.completeFromSnapshot(args) {
+		return nil
+	}
+
+	// 只构建本次执行经过的懒加载命令
+	c.materializePath(args)
+
+	c.prepareExecute(args)
+	c.localize()
+	if c.handlePluginDescribe() {
+		return nil
+	}
+	if handled, err := c.handleSnapshotRequest(); handled {
+		if err != nil {
+			c.reportError(nil, err, false)
 		}
-		// 否则调用原始帮助函数
-		if oldHelpFunc != nil {
-			oldHelpFunc(command, strs)
+		return err
+	}
+	defer func() {
+		if c.cancelTimeout != nil {
+			c.cancelTimeout()
+			c.cancelTimeout = nil
 		}
-	})
-
-	// 设置 PersistentPreRun 来处理有 Run 函数的命令
-	oldPersistentPreRunE := c.PersistentPreRunE
-	c.PersistentPreRunE = func(cmd *spf13cobra.Command, args []string) error {
-		if c.shouldShowTree() {
-			c.showTree()
-			os.Exit(0)
+	}()
+
+	if ctx != nil {
+		c.SetContext(ctx)
+	}
+
+	// 在后台检查新版本，命令结束后输出提示
+	notifyUpdate := c.startUpdateCheck()
+
+	// 错误信息由 cobrax 统一按主题输出，执行期间关闭 cobra 自带的错误与用法输出
+	silenceErrors, silenceUsage := c.SilenceErrors, c.SilenceUsage
+	c.SilenceErrors, c.SilenceUsage = true, true
+
+	// 使用传统 CLI 模式
+	c.current, c.currentArgs, c.helpErr = nil, nil, nil
+	cmd, err := c.executeC()
+	c.SilenceErrors, c.SilenceUsage = silenceErrors, silenceUsage
+
+	// 命令路径无法解析（未知命令）时 cobra 在执行任何钩子之前返回，该错误同样属于用法错误
+	if err != nil {
+		if _, _, findErr := c.Find(args); findErr != nil {
+			err = usageError(err)
 		}
-		if oldPersistentPreRunE != nil {
-			return oldPersistentPreRunE(cmd, args)
+	}
+
+	if errors.Is(err, ErrTreeShown) {
+		err = nil
+	}
+	err = timeoutError(cmd, err)
+	if err != nil && !silenceErrors && (cmd == nil || !cmd.SilenceErrors) {
+		showUsage := !silenceUsage && (cmd == nil || !cmd.SilenceUsage)
+		c.reportError(cmd, err, showUsage)
+	}
+	// 帮助函数无法返回错误，显示命令树失败时错误已经输出，这里只作为返回值
+	if err == nil {
+		err = c.helpErr
+	}
+	notifyUpdate()
+	return err
+}
+
+// prepareExecute 执行前的准备：挂载内置子命令、安装中间件与钩子
+func (c *Command) prepareExecute(args []string) {
+	// 挂载 config dump 子命令
+	if c.config != nil {
+		c.installConfigDump()
+	}
+
+	// 挂载 profile 管理子命令
+	if c.profiles != nil {
+		c.installProfileCommands()
+	}
+
+	// 挂载 version 子命令
+	if c.version != nil {
+		c.installVersionCommand()
+	}
+
+	// 挂载外部插件（仅在本次执行可能用到插件时发现）
+	if c.plugins != nil && c.needPlugins(args) {
+		c.installPlugins()
+	}
+
+	// 为所有可执行命令包装中间件链
+	c.installMiddleware(c.Command)
+
+	// 在 flag 上记录对应的环境变量名，用于树形视图和帮助信息展示
+	if c.envPrefix != "" {
+		annotateEnvFlags(c.Command, c.envPrefix)
+	}
+
+	// 设置帮助函数来检查 --tree flag（只安装一次，避免重复执行 Execute 时层层包装）
+	if !c.helpInstalled {
+		c.helpInstalled = true
+		oldHelpFunc := c.HelpFunc()
+		c.SetHelpFunc(func(command *spf13cobra.Command, strs []string) {
+			// 检查是否显示树形视图（帮助函数返回后 cobra 即结束执行）
+			if c.commandFor(command).shouldShowTree() {
+				if err := c.showTree(command); err != nil {
+					c.reportError(command, err, false)
+					c.helpErr = err
+				}
+				return
+			}
+			// 否则调用原始帮助函数（cobra 在执行期间才添加 help 命令和 --help flag，因此再翻译一次）
+			if oldHelpFunc != nil {
+				c.localize()
+				withPager(command, func() {
+					withEnvUsages(command, c.envPrefix, func() {
+						oldHelpFunc(command, strs)
+					})
+				})
+			}
+		})
+	}
+
+	// flag 解析错误标记为用法错误（只包装一次）
+	if !c.flagErrorsTyped {
+		c.flagErrorsTyped = true
+		c.SetFlagErrorFunc(flagUsageError(c.FlagErrorFunc()))
+	}
+
+	// 接管所有命令的持久化钩子，保证 --tree 处理和各级用户钩子都会执行
+	if c.hooks == nil {
+		c.hooks = make(hookRegistry)
+	}
+	c.hooks.install(c.Command, c.persistentPreRun)
+}
+
+// persistentPreRun cobrax 内部的持久化前置钩子：处理 --tree 并解析 flag 取值
+func (c *Command) persistentPreRun(cmd *spf13cobra.Command, args []string) error {
+	if c.commandFor(cmd).shouldShowTree() {
+		if err := c.showTree(cmd); err != nil {
+			return err
 		}
-		return nil
+		return ErrTreeShown
+	}
+	if c.recovery != nil {
+		c.current, c.currentArgs = cmd, commandArgs(cmd, args)
+	}
+	// cobra 在持久化钩子之后才校验必需的 flag（ValidateRequiredFlags），
+	// 因此来自环境变量、配置文件和 profile 的取值同样满足 MarkFlagRequired
+	if err := c.resolveFlagValues(cmd); err != nil {
+		return err
 	}
+	c.applyTimeout(cmd)
+	return nil
+}
 
-	// 使用传统 CLI 模式
-	return c.Command.Execute()
+// resolveFlagValues 为未在命令行设置的 flag 绑定环境变量和配置文件中的值
+func (c *Command) resolveFlagValues(cmd *spf13cobra.Command) error {
+	origins, _, err := c.resolveFlagOrigins(cmd)
+	if valueDebugEnabled() {
+		printFlagOrigins(cmd, origins, c.getTreeConfig().Theme)
+	}
+	return err
+}
+
+// resolveFlagOrigins 按 命令行 > 环境变量 > profile > 配置文件 > 默认值 的优先级解析 flag 取值
+func (c *Command) resolveFlagOrigins(cmd *spf13cobra.Command) ([]FlagOrigin, *ConfigFile, error) {
+	resolver := newFlagResolver(cmd)
+
+	if c.envPrefix != "" {
+		resolver.apply(envLayer{prefix: c.envPrefix})
+	}
+
+	// 显式选择的 profile 优先于静态的配置文件，因此先于配置文件应用
+	if c.profiles != nil {
+		layer, err := c.profileValueLayer(cmd)
+		if err != nil {
+			return nil, nil, err
+		}
+		if layer != nil {
+			resolver.apply(layer)
+		}
+	}
+
+	// --config 只能来自命令行或环境变量
+	var file *ConfigFile
+	if c.config != nil {
+		var err error
+		if file, err = c.config.load(cmd, c.controlFlagValue(cmd, configFlagName)); err != nil {
+			return nil, nil, err
+		}
+		if file != nil {
+			resolver.apply(configLayer{file: file})
+		}
+	}
+
+	origins, err := resolver.result()
+	return origins, file, err
 }
 
 // ExecuteE 执行命令并返回错误
@@ -160,14 +408,27 @@ func (c *Command) shouldShowTree() bool {
 	return false
 }
 
-// showTree 显示命令树
-func (c *Command) showTree() {
-	// 获取配置
-	config := c.getTreeConfig()
+// ErrTreeShown 已显示命令树，用于在钩子中终止后续执行
+//
+// Execute 将其视为成功；装饰器模式下由 spf13/cobra 执行，Execute 会返回该错误，调用方应视为成功。
+var ErrTreeShown = errors.New("command tree shown")
+
+// showTree 将以 cmd 为根的命令树写入 cmd 的标准输出（超过终端高度时使用分页器）
+func (c *Command) showTree(cmd *spf13cobra.Command) error {
+	// 获取配置（无效的 --tree-sort/--tree-format 作为用法错误返回）
+	target := c.commandFor(cmd)
+	if err := validateTreeFlags(target.Flags()); err != nil {
+		return err
+	}
+	config := target.getTreeConfig()
+	config.Profile = c.activeProfile(cmd)
 
-	// 显示扁平化的命令树
-	output := DisplayFlatTree(c, config)
-	fmt.Println(output)
+	// 按 --tree-format 输出命令树（默认为扁平化的命令列表）
+	output, err := renderTreeOutput(target.displayNode(), config)
+	if err != nil {
+		return err
+	}
+	return writePaged(cmd, output+"\n")
 }
 
 // getTreeConfig 获取树形配置
@@ -187,11 +448,30 @@ func (c *Command) getTreeConfig() *TreeConfig {
 		config.ShowLong = showLong
 	}
 
-	// 从 flags 读取主题名称
-	if themeName, err := c.Flags().GetString("tree-theme"); err == nil {
+	// 自身没有设置主题时沿用最近的设置了主题的祖先（通常是根命令），使子命令的输出与根命令一致
+	for p := c; p != nil; p = p.parent {
+		if p.themeSet {
+			config.Theme = p.treeConfig.Theme
+			break
+		}
+	}
+
+	// 显式指定 --tree-theme 时覆盖 WithTreeTheme/SetTreeTheme 设置的主题
+	if themeName, err := c.Flags().GetString("tree-theme"); err == nil && (c.Flags().Changed("tree-theme") || config.Theme == nil) {
 		config.Theme = GetTreeThemeByName(themeName)
 	}
 
+	if sortMode, err := c.Flags().GetString("tree-sort"); err == nil {
+		config.Sort, _ = parseTreeSortMode(sortMode)
+	}
+
+	if format, err := c.Flags().GetString("tree-format"); err == nil {
+		config.Format, _ = parseTreeFormat(format)
+	}
+
+	config.Width = treeWidth(c.Flags(), c.OutOrStdout())
+	config.Catalog = c.catalog()
+
 	return config
 }
 
@@ -201,6 +481,7 @@ func (c *Command) SetTreeTheme(theme *TreeTheme) {
 		c.treeConfig = &TreeConfig{}
 	}
 	c.treeConfig.Theme = theme
+	c.themeSet = true
 }
 
 // GetTreeConfig 获取树形配置
@@ -211,12 +492,15 @@ func (c *Command) GetTreeConfig() *TreeConfig {
 // AddCommand 添加子命令
 func (c *Command) AddCommand(cmds ...*Command) {
 	for _, cmd := range cmds {
+		c.addSubcommand(cmd)
+		c.recordRegistration(cmd.Command)
 		c.Command.AddCommand(cmd.Command)
 	}
 }
 
 // AddSpf13Command 添加原始 spf13/cobra 命令
 func (c *Command) AddSpf13Command(cmds ...*spf13cobra.Command) {
+	c.recordRegistration(cmds...)
 	c.Command.AddCommand(cmds...)
 }
 
@@ -298,7 +582,7 @@ func (c *Command) getChildren(cmd *Command) []*Command {
 		if !child.IsAvailableCommand() {
 			continue
 		}
-		children = append(children, c.wrapCommand(child))
+		children = append(children, c.commandFor(child))
 	}
 
 	return children
@@ -0,0 +1,265 @@
+package cobra
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// minColumnWidth 表格收窄时每列保留的最小宽度
+const minColumnWidth = 3
+
+// ellipsis 截断文本时追加的省略号
+const ellipsis = "…"
+
+// Theme 返回命令输出使用的主题：--tree-theme > WithTreeTheme/SetTreeTheme（自身或最近的祖先）> 默认主题
+//
+// 执行函数可以用它渲染 Table、RenderKeyValues、RenderBadge、RenderList 等组件，
+// 使输出与用户选择的主题一致：
+//
+//	fmt.Fprintln(cmd.OutOrStdout(), cobra.RenderBadge("ok", cobra.BadgeSuccess, cmd.Theme()))
+func (c *Command) Theme() *TreeTheme {
+	return c.getTreeConfig().Theme
+}
+
+// Table 带主题的表格
+//
+// 表头使用 BranchStyle，单元格使用 LeafStyle，边框使用 LineStyle。
+// 没有边框时列之间以两个空格分隔。
+type Table struct {
+	Headers        []string
+	Rows           [][]string
+	Border         bool // 绘制边框
+	Width          int  // 表格的最大总宽度，超出时依次收窄最宽的列；0 表示不限制
+	MaxColumnWidth int  // 单元格的最大宽度；0 表示不限制
+}
+
+// Render 使用主题渲染表格，超出列宽的单元格以 … 截断，theme 为 nil 时使用默认主题
+func (t *Table) Render(theme *TreeTheme) string {
+	if theme == nil {
+		theme = DefaultTreeTheme()
+	}
+
+	columns := len(t.Headers)
+	for _, row := range t.Rows {
+		columns = max(columns, len(row))
+	}
+	if columns == 0 {
+		return ""
+	}
+
+	// 补齐缺少的单元格，单元格中的换行替换为空格
+	normalize := func(cells []string) []string {
+		out := make([]string, columns)
+		for i, cell := range cells {
+			out[i] = strings.ReplaceAll(cell, "\n", " ")
+		}
+		return out
+	}
+	var headers []string
+	if len(t.Headers) > 0 {
+		headers = normalize(t.Headers)
+	}
+	rows := make([][]string, len(t.Rows))
+	for i, row := range t.Rows {
+		rows[i] = normalize(row)
+	}
+
+	widths := make([]int, columns)
+	for _, cells := range append([][]string{headers}, rows...) {
+		for i, cell := range cells {
+			widths[i] = max(widths[i], displayWidth(cell))
+		}
+	}
+	if t.MaxColumnWidth > 0 {
+		for i := range widths {
+			widths[i] = min(widths[i], max(t.MaxColumnWidth, 1))
+		}
+	}
+	if t.Width > 0 {
+		// 边框模式每列有两个空格的内边距和一条竖线，否则列之间有两个空格
+		overhead := 2 * (columns - 1)
+		if t.Border {
+			overhead = 3*columns + 1
+		}
+		shrinkColumns(widths, t.Width-overhead)
+	}
+
+	var builder strings.Builder
+	line := func(left, fill, middle, right string) {
+		parts := make([]string, columns)
+		for i, width := range widths {
+			parts[i] = strings.Repeat(fill, width+2)
+		}
+		builder.WriteString(theme.LineStyle.Render(left + strings.Join(parts, middle) + right))
+		builder.WriteString("\n")
+	}
+	row := func(cells []string, style lipgloss.Style) {
+		parts := make([]string, columns)
+		for i, cell := range cells {
+			cell = truncateText(cell, widths[i])
+			if t.Border || i < columns-1 {
+				cell = padRight(cell, widths[i])
+			}
+			parts[i] = style.Render(cell)
+		}
+		if t.Border {
+			separator := theme.LineStyle.Render("│")
+			builder.WriteString(separator + " " + strings.Join(parts, " "+separator+" ") + " " + separator)
+		} else {
+			builder.WriteString(strings.TrimRight(strings.Join(parts, "  "), " "))
+		}
+		builder.WriteString("\n")
+	}
+
+	if t.Border {
+		line("┌", "─", "┬", "┐")
+	}
+	if headers != nil {
+		row(headers, theme.BranchStyle)
+		if t.Border && len(rows) > 0 {
+			line("├", "─", "┼", "┤")
+		}
+	}
+	for _, cells := range rows {
+		row(cells, theme.LeafStyle)
+	}
+	if t.Border {
+		line("└", "─", "┴", "┘")
+	}
+	return strings.TrimSuffix(builder.String(), "\n")
+}
+
+// shrinkColumns 依次收窄最宽的列，直到总宽度不超过 available 或所有列都达到最小宽度
+func shrinkColumns(widths []int, available int) {
+	total := 0
+	for _, width := range widths {
+		total += width
+	}
+	for total > available {
+		widest := 0
+		for i, width := range widths {
+			if width > widths[widest] {
+				widest = i
+			}
+		}
+		if widths[widest] <= minColumnWidth {
+			return
+		}
+		widths[widest]--
+		total--
+	}
+}
+
+// truncateText 将文本截断到 width 以内，被截断时以 … 结尾
+func truncateText(text string, width int) string {
+	if displayWidth(text) <= width {
+		return text
+	}
+	if width <= 1 {
+		return ellipsis
+	}
+	head, _ := splitAtWidth(text, width-1)
+	if displayWidth(head) > width-1 {
+		// 第一个宽字符就放不下
+		return ellipsis
+	}
+	return head + ellipsis
+}
+
+// KeyValue 键值对
+type KeyValue struct {
+	Key   string
+	Value string
+}
+
+// RenderKeyValues 渲染键值块：键左对齐并使用 LineStyle，值使用 DescriptionStyle，
+// 多行的值与第一行对齐；theme 为 nil 时使用默认主题
+func RenderKeyValues(pairs []KeyValue, theme *TreeTheme) string {
+	if theme == nil {
+		theme = DefaultTreeTheme()
+	}
+
+	width := 0
+	for _, pair := range pairs {
+		width = max(width, displayWidth(pair.Key)+1)
+	}
+
+	lines := make([]string, 0, len(pairs))
+	for _, pair := range pairs {
+		for i, value := range strings.Split(pair.Value, "\n") {
+			key := ""
+			if i == 0 {
+				key = pair.Key + ":"
+			}
+			lines = append(lines, theme.LineStyle.Render(padRight(key, width+1))+theme.DescriptionStyle.Render(value))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// BadgeStatus 状态标识的类型
+type BadgeStatus int
+
+const (
+	// BadgeInfo 一般信息，使用 BranchStyle
+	BadgeInfo BadgeStatus = iota
+	// BadgeSuccess 成功，使用 LeafStyle
+	BadgeSuccess
+	// BadgeWarning 警告，使用 FlagStyle
+	BadgeWarning
+	// BadgeError 错误，使用 ErrorStyle
+	BadgeError
+)
+
+// RenderBadge 渲染状态标识，例如 [running]；theme 为 nil 时使用默认主题
+func RenderBadge(text string, status BadgeStatus, theme *TreeTheme) string {
+	if theme == nil {
+		theme = DefaultTreeTheme()
+	}
+
+	style := theme.BranchStyle
+	switch status {
+	case BadgeSuccess:
+		style = theme.LeafStyle
+	case BadgeWarning:
+		style = theme.FlagStyle
+	case BadgeError:
+		style = theme.ErrorStyle
+	}
+	return style.Render("[" + text + "]")
+}
+
+// ListItem 列表项，Children 为嵌套的子列表
+type ListItem struct {
+	Text     string
+	Children []ListItem
+}
+
+// listBullets 各层列表的项目符号，超过三层时循环使用
+var listBullets = []string{"•", "◦", "▪"}
+
+// RenderList 渲染嵌套列表：每层缩进两个空格，项目符号使用 LineStyle，
+// 顶层文本使用 LeafStyle，嵌套的文本使用 DescriptionStyle；theme 为 nil 时使用默认主题
+func RenderList(items []ListItem, theme *TreeTheme) string {
+	if theme == nil {
+		theme = DefaultTreeTheme()
+	}
+	var lines []string
+	renderListItems(&lines, items, 0, theme)
+	return strings.Join(lines, "\n")
+}
+
+// renderListItems 递归渲染列表项
+func renderListItems(lines *[]string, items []ListItem, depth int, theme *TreeTheme) {
+	indent := strings.Repeat("  ", depth)
+	style := theme.LeafStyle
+	if depth > 0 {
+		style = theme.DescriptionStyle
+	}
+	for _, item := range items {
+		bullet := listBullets[depth%len(listBullets)]
+		*lines = append(*lines, theme.LineStyle.Render(indent+bullet+" ")+style.Render(item.Text))
+		renderListItems(lines, item.Children, depth+1, theme)
+	}
+}
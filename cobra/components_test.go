@@ -0,0 +1,156 @@
+package cobra
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestTableRender(t *testing.T) {
+	tests := []struct {
+		name  string
+		table Table
+		want  string
+	}{
+		{
+			name:  "plain",
+			table: Table{Headers: []string{"NAME", "STATUS"}, Rows: [][]string{{"web", "running"}, {"db"}}},
+			want: `
+NAME  STATUS
+web   running
+db
+`,
+		},
+		{
+			name:  "border",
+			table: Table{Headers: []string{"NAME", "PORT"}, Rows: [][]string{{"web", "8080"}, {"数据库", "5432"}}, Border: true},
+			want: `
+┌────────┬──────┐
+│ NAME   │ PORT │
+├────────┼──────┤
+│ web    │ 8080 │
+│ 数据库 │ 5432 │
+└────────┴──────┘
+`,
+		},
+		{
+			name:  "max column width",
+			table: Table{Rows: [][]string{{"a very long description", "x"}}, MaxColumnWidth: 8},
+			want: `
+a very …  x
+`,
+		},
+		{
+			name:  "width shrinks the widest column",
+			table: Table{Headers: []string{"KEY", "VALUE"}, Rows: [][]string{{"endpoint", "https://api.example.com/v1"}}, Border: true, Width: 30},
+			want: `
+┌──────────┬─────────────────┐
+│ KEY      │ VALUE           │
+├──────────┼─────────────────┤
+│ endpoint │ https://api.ex… │
+└──────────┴─────────────────┘
+`,
+		},
+		{
+			name:  "empty",
+			table: Table{},
+			want:  "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := strings.TrimSuffix(strings.TrimPrefix(tt.want, "\n"), "\n")
+			if got := tt.table.Render(nil); got != want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, want)
+			}
+			if tt.table.Width > 0 {
+				for _, line := range strings.Split(tt.table.Render(nil), "\n") {
+					if displayWidth(line) > tt.table.Width {
+						t.Errorf("line %q is wider than %d", line, tt.table.Width)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestTruncateText(t *testing.T) {
+	tests := []struct {
+		text  string
+		width int
+		want  string
+	}{
+		{"short", 10, "short"},
+		{"exactly", 7, "exactly"},
+		{"truncated", 6, "trunc…"},
+		{"监听地址", 5, "监听…"},
+		{"监听地址", 2, "…"},
+		{"abc", 1, "…"},
+	}
+	for _, tt := range tests {
+		if got := truncateText(tt.text, tt.width); got != tt.want {
+			t.Errorf("truncateText(%q, %d) = %q, want %q", tt.text, tt.width, got, tt.want)
+		}
+	}
+}
+
+func TestRenderKeyValues(t *testing.T) {
+	got := RenderKeyValues([]KeyValue{{"host", "0.0.0.0"}, {"port", "8080"}, {"workers", "4\n(auto)"}}, nil)
+	want := "host:    0.0.0.0\nport:    8080\nworkers: 4\n         (auto)"
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestRenderList(t *testing.T) {
+	got := RenderList([]ListItem{
+		{Text: "server", Children: []ListItem{{Text: "start", Children: []ListItem{{Text: "--port"}}}, {Text: "stop"}}},
+		{Text: "client"},
+	}, nil)
+	want := "• server\n  ◦ start\n    ▪ --port\n  ◦ stop\n• client"
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestThemeOverride(t *testing.T) {
+	cmd := NewCommand("app", WithTreeTheme(NordTreeTheme()))
+	if cmd.Theme().RootStyle.GetForeground() != NordTreeTheme().RootStyle.GetForeground() {
+		t.Errorf("WithTreeTheme ignored without --tree-theme")
+	}
+
+	if err := cmd.Flags().Set("tree-theme", "dracula"); err != nil {
+		t.Fatal(err)
+	}
+	if cmd.Theme().RootStyle.GetForeground() != DraculaTreeTheme().RootStyle.GetForeground() {
+		t.Errorf("--tree-theme does not override WithTreeTheme")
+	}
+}
+
+func TestThemeInheritedBySubcommands(t *testing.T) {
+	t.Setenv("COBRA_TREE", "")
+
+	var got *TreeTheme
+	var table bytes.Buffer
+	sub := NewCommand("sub", WithRunE(func(cmd *Command, args []string) error {
+		got = cmd.Theme()
+		return cmd.PrintResult([]struct{ Name string }{{"a"}})
+	}))
+	dracula := DraculaTreeTheme()
+	root := NewCommand("app", WithTreeTheme(dracula), WithOutput(OutputTable), WithSubcommands(sub))
+	root.SetOut(&table)
+	root.SetErr(&bytes.Buffer{})
+	root.SetArgs([]string{"sub"})
+	if err := root.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got != dracula {
+		t.Errorf("sub.Theme() is not the root's WithTreeTheme theme")
+	}
+	want := (&Table{Headers: []string{"NAME"}, Rows: [][]string{{"a"}}}).Render(dracula)
+	if strings.TrimSpace(table.String()) != strings.TrimSpace(want) {
+		t.Errorf("PrintResult in sub:\n%s\nwant:\n%s", table.String(), want)
+	}
+}
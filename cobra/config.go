@@ -0,0 +1,300 @@
+package cobra
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	spf13cobra "github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// configFlagName 指定配置文件路径的 flag 名称
+const configFlagName = "config"
+
+// ConfigDecoder 配置文件解码器，将文件内容解码为键值映射
+type ConfigDecoder func(data []byte) (map[string]interface{}, error)
+
+// configDecoders 已注册的解码器（按扩展名）
+var configDecoders = map[string]ConfigDecoder{
+	"json": decodeJSONConfig,
+}
+
+// RegisterConfigDecoder 注册配置文件解码器
+//
+// ext 为不带点的扩展名，例如 "yaml"、"toml"。
+// 搜索配置文件时会依次尝试 json 和其它已注册的扩展名。
+func RegisterConfigDecoder(ext string, decoder ConfigDecoder) {
+	configDecoders[strings.TrimPrefix(ext, ".")] = decoder
+}
+
+// decodeJSONConfig 内置的 JSON 解码器
+func decodeJSONConfig(data []byte) (map[string]interface{}, error) {
+	var values map[string]interface{}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// configExtensions 返回搜索时尝试的扩展名（json 优先，其余按字母序）
+func configExtensions() []string {
+	exts := make([]string, 0, len(configDecoders))
+	for ext := range configDecoders {
+		if ext != "json" {
+			exts = append(exts, ext)
+		}
+	}
+	sort.Strings(exts)
+	return append([]string{"json"}, exts...)
+}
+
+// configSettings 配置文件设置
+type configSettings struct {
+	name  string   // 配置文件基础名（不含扩展名）
+	paths []string // 搜索路径，为空时使用默认路径
+}
+
+// WithConfigFile 启用配置文件支持
+//
+// 根命令会增加 --config flag 用于显式指定文件；未指定时依次在以下位置查找
+// <name>.json（以及其它已注册解码器的扩展名）：
+//
+//	$XDG_CONFIG_HOME/<root>/   用户配置目录
+//	./                          当前工作目录
+//
+// 也可以通过 paths 自定义搜索路径。配置文件中的顶层键对所有命令生效，
+// 以命令完整路径（GetCommandFullPath）为键的对象只对该命令及其子命令生效：
+//
+//	{
+//	    "verbose": true,
+//	    "myapp server": {"port": 9090}
+//	}
+//
+// 优先级为：命令行 > 环境变量 > 配置文件 > 默认值。
+func WithConfigFile(name string, paths ...string) CommandOption {
+	return func(c *Command) {
+		c.config = &configSettings{name: name, paths: paths}
+		if c.PersistentFlags().Lookup(configFlagName) == nil {
+			c.PersistentFlags().String(configFlagName, "", defaultMessages[MsgFlagConfig])
+			markFrameworkFlags(c.PersistentFlags(), configFlagName)
+		}
+	}
+}
+
+// searchPaths 返回配置文件搜索路径
+func (s *configSettings) searchPaths(root *spf13cobra.Command) []string {
+	if len(s.paths) > 0 {
+		return s.paths
+	}
+
+	var paths []string
+	if dir, err := os.UserConfigDir(); err == nil {
+		paths = append(paths, filepath.Join(dir, root.Name()))
+	}
+	return append(paths, ".")
+}
+
+// ConfigFile 已加载的配置文件
+type ConfigFile struct {
+	Path   string
+	Values map[string]interface{}
+}
+
+// load 加载配置文件：优先 --config 指定的路径，否则按搜索路径查找
+// 未找到配置文件时返回 nil
+func (s *configSettings) load(cmd *spf13cobra.Command, path string) (*ConfigFile, error) {
+	if path != "" {
+		return readConfigFile(path)
+	}
+
+	for _, dir := range s.searchPaths(cmd.Root()) {
+		for _, ext := range configExtensions() {
+			path := filepath.Join(dir, s.name+"."+ext)
+			if _, err := os.Stat(path); err == nil {
+				return readConfigFile(path)
+			}
+		}
+	}
+	return nil, nil
+}
+
+// readConfigFile 读取并解码配置文件
+func readConfigFile(path string) (*ConfigFile, error) {
+	ext := strings.TrimPrefix(filepath.Ext(path), ".")
+	decoder, ok := configDecoders[ext]
+	if !ok {
+		return nil, fmt.Errorf("unsupported config file format %q: %s", ext, path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read config file: %w", err)
+	}
+
+	values, err := decoder(data)
+	if err != nil {
+		return nil, fmt.Errorf("parse config file %s: %w", path, err)
+	}
+	return &ConfigFile{Path: path, Values: values}, nil
+}
+
+// Lookup 查找命令的 flag 值：命令自身的段 > 祖先命令的段 > 顶层键
+// 返回值为字符串形式，section 为命中的段名（顶层键时为空）
+func (f *ConfigFile) Lookup(cmd *spf13cobra.Command, flagName string) (value string, section string, ok bool) {
+	for current := cmd; current != nil; current = current.Parent() {
+		path := GetCommandFullPath(current)
+		if values, isSection := f.Values[path].(map[string]interface{}); isSection {
+			if value, ok := configValueString(values[flagName]); ok {
+				return value, path, true
+			}
+		}
+	}
+
+	value, ok = configValueString(f.Values[flagName])
+	return value, "", ok
+}
+
+// configValueString 将配置值转换为 flag 可接受的字符串
+func configValueString(v interface{}) (string, bool) {
+	switch value := v.(type) {
+	case nil, map[string]interface{}:
+		return "", false
+	case string:
+		return value, true
+	case bool:
+		return strconv.FormatBool(value), true
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64), true
+	case []interface{}:
+		items := make([]string, 0, len(value))
+		for _, item := range value {
+			s, ok := configValueString(item)
+			if !ok {
+				return "", false
+			}
+			items = append(items, s)
+		}
+		return strings.Join(items, ","), true
+	default:
+		return fmt.Sprint(value), true
+	}
+}
+
+// configLayer 配置文件取值层
+type configLayer struct {
+	file *ConfigFile
+}
+
+func (l configLayer) source() ValueSource {
+	return SourceConfig
+}
+
+func (l configLayer) lookup(cmd *spf13cobra.Command, flag *pflag.Flag) (string, string, bool) {
+	if flag.Name == configFlagName {
+		return "", "", false
+	}
+
+	value, section, ok := l.file.Lookup(cmd, flag.Name)
+	if !ok {
+		return "", "", false
+	}
+
+	detail := l.file.Path
+	if section != "" {
+		detail += " [" + section + "]"
+	}
+	return value, detail, true
+}
+
+// installConfigDump 在根命令的 config 命令下挂载 dump 子命令
+func (c *Command) installConfigDump() {
+	var configCmd *spf13cobra.Command
+	for _, child := range c.Commands() {
+		if child.Name() == "config" {
+			configCmd = child
+			break
+		}
+	}
+	if configCmd == nil {
+		configCmd = &spf13cobra.Command{
+			Use:   "config",
+			Short: defaultMessages[MsgCmdConfig],
+		}
+		c.Command.AddCommand(configCmd)
+	}
+
+	for _, child := range configCmd.Commands() {
+		if child.Name() == "dump" {
+			return
+		}
+	}
+
+	configCmd.AddCommand(&spf13cobra.Command{
+		Use:                "dump [command path] [flags]",
+		Short:              defaultMessages[MsgCmdConfigDump],
+		Long:               defaultMessages[MsgCmdConfigDumpLong],
+		Example:            "  " + c.Name() + " config dump server --port 9090",
+		DisableFlagParsing: true,
+		RunE: func(cmd *spf13cobra.Command, args []string) error {
+			return c.runConfigDump(cmd, args)
+		},
+	})
+}
+
+// runConfigDump 输出目标命令每个 flag 的有效值及来源
+func (c *Command) runConfigDump(cmd *spf13cobra.Command, args []string) error {
+	for _, arg := range args {
+		if arg == "-h" || arg == "--help" {
+			return cmd.Help()
+		}
+	}
+
+	target, rest, err := c.Command.Find(args)
+	if err != nil {
+		return err
+	}
+	if err := target.ParseFlags(rest); err != nil {
+		return err
+	}
+
+	origins, file, err := c.resolveFlagOrigins(target)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	theme := c.getTreeConfig().Theme
+	configPath := "none"
+	if file != nil {
+		configPath = file.Path
+	}
+	fmt.Fprintln(out, theme.RootStyle.Render("Command: "+GetCommandFullPath(target)))
+	fmt.Fprintln(out, theme.LineStyle.Render("Config file: "+configPath))
+	fmt.Fprintln(out)
+
+	// 计算列宽
+	flagWidth, valueWidth := len("FLAG"), len("VALUE")
+	for _, origin := range origins {
+		flagWidth = max(flagWidth, len(origin.Flag)+2)
+		valueWidth = max(valueWidth, len(origin.Value))
+	}
+
+	header := fmt.Sprintf("%-*s  %-*s  %s", flagWidth, "FLAG", valueWidth, "VALUE", "SOURCE")
+	fmt.Fprintln(out, theme.BranchStyle.Render(header))
+	for _, origin := range origins {
+		source := string(origin.Source)
+		if origin.Detail != "" {
+			source += " (" + origin.Detail + ")"
+		}
+		line := theme.FlagStyle.Render(fmt.Sprintf("%-*s", flagWidth, "--"+origin.Flag)) + "  " +
+			theme.LeafStyle.Render(fmt.Sprintf("%-*s", valueWidth, origin.Value)) + "  " +
+			theme.LineStyle.Render(source)
+		fmt.Fprintln(out, line)
+	}
+	return nil
+}
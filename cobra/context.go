@@ -0,0 +1,134 @@
+package cobra
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	spf13cobra "github.com/spf13/cobra"
+)
+
+const (
+	// ExitCodeInterrupted 收到 SIGINT/SIGTERM 后命令正常退出时的退出码
+	ExitCodeInterrupted = 130
+	// ExitCodeForceQuit 第二次收到中断信号强制退出时的退出码
+	ExitCodeForceQuit = 137
+)
+
+// ErrInterrupted 命令因 SIGINT/SIGTERM 被取消
+var ErrInterrupted = errors.New("interrupted")
+
+// timeoutFlagName 超时时间 flag 名称
+const timeoutFlagName = "timeout"
+
+// WithTimeout 为根命令增加 --timeout flag，超时后取消传给执行函数的 context
+//
+// d 为默认超时时间，0 表示不限制。
+func WithTimeout(d time.Duration) CommandOption {
+	return func(c *Command) {
+		c.timeout = true
+		if c.PersistentFlags().Lookup(timeoutFlagName) == nil {
+			c.PersistentFlags().Duration(timeoutFlagName, d, defaultMessages[MsgFlagTimeout])
+			markFrameworkFlags(c.PersistentFlags(), timeoutFlagName)
+		}
+	}
+}
+
+// WithRunContext 设置接收 context 的命令执行函数
+//
+// 通过 ExecuteContext 执行时，该 context 会在收到 SIGINT/SIGTERM 或超过 --timeout 后被取消。
+func WithRunContext(fn func(ctx context.Context, cmd *Command, args []string) error) CommandOption {
+	return func(c *Command) {
+		c.Command.RunE = func(cmd *spf13cobra.Command, args []string) error {
+			wrappedCmd := c.wrapCommand(cmd)
+			return fn(commandContext(cmd), wrappedCmd, args)
+		}
+	}
+}
+
+// commandContext 获取命令的 context，未设置时返回 context.Background()
+func commandContext(cmd *spf13cobra.Command) context.Context {
+	if ctx := cmd.Context(); ctx != nil {
+		return ctx
+	}
+	return context.Background()
+}
+
+// ExecuteContext 使用 context 执行命令，并安装信号处理
+//
+// 第一次收到 SIGINT/SIGTERM 时取消 context，等待执行函数自行退出，返回的错误包含 ErrInterrupted；
+// 再次收到信号时以 ExitCodeForceQuit 立即退出进程。
+func (c *Command) ExecuteContext(ctx context.Context) error {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	done := make(chan struct{})
+	defer close(done)
+	go c.watchSignals(signals, done, cancel)
+
+	err := c.execute(ctx)
+	if err != nil && errors.Is(context.Cause(ctx), ErrInterrupted) && errors.Is(err, context.Canceled) {
+		return fmt.Errorf("%w: %v", ErrInterrupted, err)
+	}
+	return err
+}
+
+// watchSignals 处理中断信号：第一次取消 context，第二次强制退出
+func (c *Command) watchSignals(signals <-chan os.Signal, done <-chan struct{}, cancel context.CancelCauseFunc) {
+	select {
+	case sig := <-signals:
+		cancel(ErrInterrupted)
+		theme := c.getTreeConfig().Theme
+		c.PrintErrln(theme.LineStyle.Render(fmt.Sprintf("Received %s, shutting down... (press Ctrl-C again to force quit)", sig)))
+	case <-done:
+		return
+	}
+
+	select {
+	case <-signals:
+		os.Exit(ExitCodeForceQuit)
+	case <-done:
+	}
+}
+
+// applyTimeout 根据 --timeout 为当前命令的 context 设置超时
+func (c *Command) applyTimeout(cmd *spf13cobra.Command) {
+	if !c.timeout {
+		return
+	}
+
+	// 读取根命令上的 flag，避免被子命令同名的本地 flag 遮蔽
+	d, err := c.PersistentFlags().GetDuration(timeoutFlagName)
+	if err != nil || d <= 0 {
+		return
+	}
+
+	parent := commandContext(cmd)
+	ctx, cancel := context.WithTimeoutCause(parent, d, fmt.Errorf("command timed out after %s", d))
+	cmd.SetContext(ctx)
+
+	// 执行结束后释放 context 并恢复原来的 context，避免重复执行时沿用已超时的 context
+	c.cancelTimeout = func() {
+		cancel()
+		cmd.SetContext(parent)
+	}
+}
+
+// timeoutError 为超时导致的错误补充超时说明
+func timeoutError(cmd *spf13cobra.Command, err error) error {
+	if err == nil || cmd == nil || !errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	if cause := context.Cause(commandContext(cmd)); cause != nil && !errors.Is(cause, context.DeadlineExceeded) {
+		return fmt.Errorf("%v: %w", cause, err)
+	}
+	return err
+}
@@ -0,0 +1,48 @@
+package cobra
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimeoutCancelsContext(t *testing.T) {
+	t.Setenv("COBRA_TREE", "")
+
+	// spf13/cobra 会沿用子命令上一次执行的 context，每次执行使用新的命令树
+	execute := func(args ...string) error {
+		root := NewCommand("timeouttest", WithTimeout(0), WithSubcommands(
+			NewCommand("wait", WithRunContext(func(ctx context.Context, cmd *Command, args []string) error {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(50 * time.Millisecond):
+					return nil
+				}
+			})),
+		))
+		root.SetOut(&bytes.Buffer{})
+		root.SetErr(&bytes.Buffer{})
+		root.SetArgs(args)
+		return root.ExecuteContext(context.Background())
+	}
+
+	// 默认值 0 表示不限制
+	if err := execute("wait"); err != nil {
+		t.Fatalf("Execute() without --timeout = %v", err)
+	}
+
+	err := execute("wait", "--timeout", "10ms")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Execute() with --timeout = %v, want context.DeadlineExceeded", err)
+	}
+	if !strings.Contains(err.Error(), "timed out after 10ms") {
+		t.Errorf("error = %q, want the timeout cause", err)
+	}
+	if got := ExitCode(err); got != ExitCodeTimeout {
+		t.Errorf("ExitCode() = %d, want %d", got, ExitCodeTimeout)
+	}
+}
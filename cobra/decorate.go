@@ -1,7 +1,6 @@
 package cobra
 
 import (
-	"fmt"
 	"os"
 
 	spf13cobra "github.com/spf13/cobra"
@@ -41,7 +40,8 @@ func Enhance(cmd *spf13cobra.Command, opts ...EnhanceOption) *spf13cobra.Command
 	// 添加 tree flags
 	addTreeFlags(cmd)
 
-	// 包装 PreRun/E 来处理 tree flag
+	// 包装帮助函数和持久化钩子来处理 tree flag
+	// 注意：钩子在调用 Enhance 时对整棵树安装，之后添加的子命令需要在 Enhance 之前完成
 	addTreeHandler(cmd, config)
 
 	return cmd
@@ -65,10 +65,14 @@ func WithTreeEnabled(enabled bool) EnhanceOption {
 func addTreeFlags(cmd *spf13cobra.Command) {
 	// 只添加到根命令，避免重复添加
 	if cmd.Flags().Lookup("tree") == nil {
-		cmd.Flags().Bool("tree", false, "Display command tree")
-		cmd.Flags().String("tree-theme", "default", "Tree theme (default, dracula, nord, monokai, light)")
-		cmd.Flags().Bool("tree-flags", false, "Show flags in tree view")
-		cmd.Flags().Bool("tree-long", true, "Show long descriptions in tree view")
+		cmd.Flags().Bool("tree", false, defaultMessages[MsgFlagTree])
+		cmd.Flags().String("tree-theme", "default", defaultMessages[MsgFlagTreeTheme])
+		cmd.Flags().Bool("tree-flags", false, defaultMessages[MsgFlagTreeFlags])
+		cmd.Flags().Bool("tree-long", true, defaultMessages[MsgFlagTreeLong])
+		cmd.Flags().String("tree-sort", string(TreeSortGroup), defaultMessages[MsgFlagTreeSort])
+		cmd.Flags().String("tree-format", string(TreeFormatText), defaultMessages[MsgFlagTreeFormat])
+		cmd.Flags().Int("tree-width", 0, defaultMessages[MsgFlagTreeWidth])
+		cmd.Flags().Bool(noPagerFlagName, false, defaultMessages[MsgFlagNoPager])
 	}
 }
 
@@ -81,40 +85,48 @@ func addTreeHandler(cmd *spf13cobra.Command, config *EnhanceConfig) {
 	cmd.SetHelpFunc(func(c *spf13cobra.Command, strs []string) {
 		// 检查是否需要显示树（--tree 或 --tree-flags）
 		if shouldShowTreeForCmd(c) {
-			wrappedCmd := &Command{
-				Command:    c,
-				treeConfig: &TreeConfig{Theme: config.TreeTheme},
-			}
-			treeConfig := wrappedCmd.getTreeConfig()
-			output := DisplayFlatTree(wrappedCmd, treeConfig)
-			fmt.Println(output)
-			os.Exit(0)
+			showEnhancedTree(c, config)
+			return
 		}
 		// 否则调用原始帮助函数
 		if oldHelpFunc != nil {
-			oldHelpFunc(c, strs)
+			withPager(c, func() {
+				oldHelpFunc(c, strs)
+			})
 		}
 	})
 
-	// 设置 PersistentPreRun 来处理有子命令的情况
-	oldPersistentPreRunE := cmd.PersistentPreRunE
-	cmd.PersistentPreRunE = func(c *spf13cobra.Command, args []string) error {
+	// 接管整棵树的持久化钩子，子命令自定义 PersistentPreRun 时 --tree 处理依然生效
+	hooks := make(hookRegistry)
+	hooks.install(cmd, func(c *spf13cobra.Command, args []string) error {
 		// 检查是否需要显示树（--tree 或 --tree-flags）
+		// 装饰器模式下由原始的 spf13/cobra 执行命令，返回 ErrTreeShown 终止执行，并关闭 cobra 对它的错误与用法输出
 		if shouldShowTreeForCmd(c) {
-			wrappedCmd := &Command{
-				Command:    c,
-				treeConfig: &TreeConfig{Theme: config.TreeTheme},
-			}
-			treeConfig := wrappedCmd.getTreeConfig()
-			output := DisplayFlatTree(wrappedCmd, treeConfig)
-			fmt.Println(output)
-			os.Exit(0)
-		}
-		if oldPersistentPreRunE != nil {
-			return oldPersistentPreRunE(c, args)
+			showEnhancedTree(c, config)
+			c.SilenceErrors, c.SilenceUsage = true, true
+			return ErrTreeShown
 		}
 		return nil
+	})
+}
+
+// showEnhancedTree 显示装饰器模式下的命令树
+func showEnhancedTree(cmd *spf13cobra.Command, config *EnhanceConfig) {
+	wrappedCmd := &Command{
+		Command:    cmd,
+		treeConfig: &TreeConfig{Theme: config.TreeTheme},
+	}
+	treeConfig := wrappedCmd.getTreeConfig()
+	if err := validateTreeFlags(cmd.Flags()); err != nil {
+		cmd.PrintErrln(RenderError(err, treeConfig.Theme))
+		return
+	}
+	output, err := renderTreeOutput(wrappedCmd.displayNode(), treeConfig)
+	if err != nil {
+		cmd.PrintErrln(RenderError(err, treeConfig.Theme))
+		return
 	}
+	_ = writePaged(cmd, output+"\n")
 }
 
 // shouldShowTreeForCmd 判断是否应该显示树形视图（用于装饰器模式）
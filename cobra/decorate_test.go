@@ -0,0 +1,40 @@
+package cobra
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	spf13cobra "github.com/spf13/cobra"
+)
+
+func TestEnhanceTreeStopsExecution(t *testing.T) {
+	ran := false
+	root := &spf13cobra.Command{Use: "legacy"}
+	root.AddCommand(&spf13cobra.Command{
+		Use:   "sub",
+		Short: "Legacy subcommand",
+		Run:   func(cmd *spf13cobra.Command, args []string) { ran = true },
+	})
+	Enhance(root)
+
+	var stdout, stderr bytes.Buffer
+	root.SetOut(&stdout)
+	root.SetErr(&stderr)
+	root.SetArgs([]string{"sub"})
+	t.Setenv("COBRA_TREE", "true")
+
+	if err := root.Execute(); !errors.Is(err, ErrTreeShown) {
+		t.Fatalf("Execute() = %v, want ErrTreeShown", err)
+	}
+	if ran {
+		t.Error("Run was called after the tree was shown")
+	}
+	if !strings.Contains(stdout.String(), "sub") {
+		t.Errorf("tree not shown: %q", stdout.String())
+	}
+	if stderr.Len() > 0 {
+		t.Errorf("unexpected stderr: %q", stderr.String())
+	}
+}
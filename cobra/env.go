@@ -0,0 +1,134 @@
+package cobra
+
+import (
+	"os"
+	"strings"
+
+	spf13cobra "github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// envAnnotation 记录 flag 对应环境变量名的 flag 注解键
+const envAnnotation = "cobrax_env"
+
+// WithEnvPrefix 启用环境变量自动绑定
+//
+// 对命令树中的每个 flag，若命令行未设置，则依次读取：
+//
+//	<PREFIX>_<CMD_PATH>_<FLAG>   例如 MYAPP_SERVER_PORT
+//	<PREFIX>_<FLAG>              例如 MYAPP_PORT
+//
+// 优先级为：命令行 > 命令路径环境变量 > 全局环境变量 > 默认值。
+// 设置 COBRA_ENV_DEBUG=true 可输出每个 flag 的取值来源。
+func WithEnvPrefix(prefix string) CommandOption {
+	return func(c *Command) {
+		c.envPrefix = prefix
+	}
+}
+
+// envVarNames 返回 flag 对应的环境变量名（按优先级排序）
+func envVarNames(prefix string, cmd *spf13cobra.Command, flagName string) []string {
+	var names []string
+	if path := envCommandPath(cmd); path != "" {
+		names = append(names, envKey(prefix+"_"+path+"_"+flagName))
+	}
+	return append(names, envKey(prefix+"_"+flagName))
+}
+
+// envCommandPath 获取命令相对根命令的路径（不含根命令名）
+func envCommandPath(cmd *spf13cobra.Command) string {
+	var parts []string
+	for current := cmd; current != nil && current.HasParent(); current = current.Parent() {
+		parts = append([]string{current.Name()}, parts...)
+	}
+	return strings.Join(parts, "_")
+}
+
+// envKey 规范化环境变量名（大写，非字母数字替换为下划线）
+func envKey(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, s)
+}
+
+// envControlFlags 可以通过 <PREFIX>_<FLAG> 环境变量设置的 cobrax 功能 flag
+var envControlFlags = map[string]bool{configFlagName: true, profileFlagName: true, langFlagName: true}
+
+// annotateEnvFlags 在命令树的每个 flag 上记录对应的环境变量名
+func annotateEnvFlags(cmd *spf13cobra.Command, prefix string) {
+	cmd.LocalFlags().VisitAll(func(flag *pflag.Flag) {
+		if skipValueBinding(flag) && !(envControlFlags[flag.Name] && !cmd.HasParent()) {
+			return
+		}
+		if flag.Annotations == nil {
+			flag.Annotations = make(map[string][]string)
+		}
+		flag.Annotations[envAnnotation] = envVarNames(prefix, cmd, flag.Name)
+	})
+
+	for _, child := range cmd.Commands() {
+		annotateEnvFlags(child, prefix)
+	}
+}
+
+// envLayer 环境变量取值层
+type envLayer struct {
+	prefix string
+}
+
+func (l envLayer) source() ValueSource {
+	return SourceEnv
+}
+
+func (l envLayer) lookup(cmd *spf13cobra.Command, flag *pflag.Flag) (string, string, bool) {
+	for _, name := range envVarNames(l.prefix, cmd, flag.Name) {
+		if value, ok := os.LookupEnv(name); ok {
+			return value, name, true
+		}
+	}
+	return "", "", false
+}
+
+// envUsageNames 返回在 cmd 的帮助信息中为 flag 显示的环境变量名，与取值时的查找顺序一致
+//
+// 继承自父命令的 flag 在子命令上按子命令的路径查找（MYAPP_SERVER_PORT 优先于 MYAPP_PORT），
+// 而 --config 等控制类 flag 始终只读取 <PREFIX>_<FLAG>，因此直接使用注解中记录的名称。
+func envUsageNames(cmd *spf13cobra.Command, prefix string, flag *pflag.Flag) []string {
+	names := flag.Annotations[envAnnotation]
+	if len(names) == 0 || (envControlFlags[flag.Name] && cmd.Root().PersistentFlags().Lookup(flag.Name) == flag) {
+		return names
+	}
+	return envVarNames(prefix, cmd, flag.Name)
+}
+
+// withEnvUsages 临时在 flag 说明后追加环境变量名，用于帮助信息输出
+func withEnvUsages(cmd *spf13cobra.Command, prefix string, fn func()) {
+	restore := make(map[*pflag.Flag]string)
+	visit := func(flag *pflag.Flag) {
+		names := envUsageNames(cmd, prefix, flag)
+		if len(names) == 0 {
+			return
+		}
+		if _, done := restore[flag]; done {
+			return
+		}
+		restore[flag] = flag.Usage
+		flag.Usage += " [env: " + strings.Join(names, ", ") + "]"
+	}
+	cmd.LocalFlags().VisitAll(visit)
+	cmd.InheritedFlags().VisitAll(visit)
+
+	defer func() {
+		for flag, usage := range restore {
+			flag.Usage = usage
+		}
+	}()
+	fn()
+}
@@ -0,0 +1,288 @@
+package cobra
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	spf13cobra "github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// ErrorCategory 错误类别
+type ErrorCategory string
+
+const (
+	// CategoryGeneral 一般错误
+	CategoryGeneral ErrorCategory = "general"
+	// CategoryUsage 用法错误（未知命令、flag 错误、参数校验失败等）
+	CategoryUsage ErrorCategory = "usage"
+	// CategoryNotFound 请求的资源不存在
+	CategoryNotFound ErrorCategory = "not_found"
+	// CategoryInternal 程序内部错误
+	CategoryInternal ErrorCategory = "internal"
+)
+
+// 进程退出码
+const (
+	ExitCodeOK       = 0
+	ExitCodeError    = 1
+	ExitCodeUsage    = 2
+	ExitCodeNotFound = 3
+	ExitCodeInternal = 70  // 对应 sysexits 的 EX_SOFTWARE
+	ExitCodeTimeout  = 124 // 与 timeout(1) 一致
+)
+
+// Error 携带退出码、类别和提示信息的错误
+//
+// 使用示例：
+//
+//	return cobra.NewNotFoundError("profile %q does not exist", name).
+//	    WithHint("run 'myapp profile list' to see available profiles")
+type Error struct {
+	Category ErrorCategory // 错误类别
+	Code     int           // 进程退出码，0 表示使用类别的默认退出码
+	Message  string        // 面向用户的错误信息
+	Hint     string        // 面向用户的提示
+	Err      error         // 原始错误
+}
+
+// Error 实现 error 接口
+func (e *Error) Error() string {
+	switch {
+	case e.Message != "" && e.Err != nil:
+		return e.Message + ": " + e.Err.Error()
+	case e.Message != "":
+		return e.Message
+	case e.Err != nil:
+		return e.Err.Error()
+	default:
+		return string(e.Category) + " error"
+	}
+}
+
+// Unwrap 返回原始错误
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// WithHint 设置提示信息
+func (e *Error) WithHint(hint string) *Error {
+	e.Hint = hint
+	return e
+}
+
+// WithCode 设置退出码
+func (e *Error) WithCode(code int) *Error {
+	e.Code = code
+	return e
+}
+
+// ExitCode 返回该错误对应的进程退出码
+func (e *Error) ExitCode() int {
+	if e.Code != 0 {
+		return e.Code
+	}
+	switch e.Category {
+	case CategoryUsage:
+		return ExitCodeUsage
+	case CategoryNotFound:
+		return ExitCodeNotFound
+	case CategoryInternal:
+		return ExitCodeInternal
+	default:
+		return ExitCodeError
+	}
+}
+
+// NewUsageError 创建用法错误
+func NewUsageError(format string, args ...interface{}) *Error {
+	return &Error{Category: CategoryUsage, Message: fmt.Sprintf(format, args...)}
+}
+
+// NewNotFoundError 创建资源不存在错误
+func NewNotFoundError(format string, args ...interface{}) *Error {
+	return &Error{Category: CategoryNotFound, Message: fmt.Sprintf(format, args...)}
+}
+
+// NewInternalError 创建内部错误
+func NewInternalError(err error) *Error {
+	return &Error{Category: CategoryInternal, Err: err}
+}
+
+// WrapError 以指定类别包装错误
+func WrapError(err error, category ErrorCategory) *Error {
+	return &Error{Category: category, Err: err}
+}
+
+// AsError 将任意错误转换为 *Error
+//
+// 已经是 *Error 的直接返回；校验错误、pflag 的用法错误、中断与超时会识别为对应的类别和退出码。
+// 通过 Execute 执行时，cobra 的参数校验、flag 解析和未知命令错误在产生时即已标记为用法错误。
+func AsError(err error) *Error {
+	if err == nil {
+		return nil
+	}
+
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+
+	var verr *ValidationError
+	if errors.As(err, &verr) || isUsageError(err) {
+		return &Error{Category: CategoryUsage, Err: err}
+	}
+
+	switch {
+	case errors.Is(err, ErrInterrupted):
+		return &Error{Category: CategoryGeneral, Code: ExitCodeInterrupted, Err: err}
+	case errors.Is(err, context.DeadlineExceeded):
+		return &Error{Category: CategoryGeneral, Code: ExitCodeTimeout, Err: err}
+	}
+
+	return &Error{Category: CategoryGeneral, Err: err}
+}
+
+// ExitCode 返回错误对应的进程退出码，err 为 nil 时返回 0
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitCodeOK
+	}
+	return AsError(err).ExitCode()
+}
+
+// isUsageError 判断是否为 pflag 产生的用法错误
+func isUsageError(err error) bool {
+	var notExist *pflag.NotExistError
+	var valueRequired *pflag.ValueRequiredError
+	var invalidValue *pflag.InvalidValueError
+	var invalidSyntax *pflag.InvalidSyntaxError
+	return errors.As(err, &notExist) || errors.As(err, &valueRequired) ||
+		errors.As(err, &invalidValue) || errors.As(err, &invalidSyntax)
+}
+
+// usageError 将 cobra 产生的错误标记为用法错误，已经是 *Error 的保持不变
+func usageError(err error) error {
+	var e *Error
+	if err == nil || errors.As(err, &e) {
+		return err
+	}
+	return &Error{Category: CategoryUsage, Err: err}
+}
+
+// flagUsageError 包装 FlagErrorFunc，将 flag 解析错误标记为用法错误
+func flagUsageError(next func(*spf13cobra.Command, error) error) func(*spf13cobra.Command, error) error {
+	return func(cmd *spf13cobra.Command, err error) error {
+		return usageError(next(cmd, err))
+	}
+}
+
+// typeUsageErrors 在来源处将命令的参数校验、必需 flag 和 flag 组校验错误标记为用法错误
+//
+// cobra 在 PreRun 之后才校验必需的 flag 和 flag 组，这里在 PreRun 末尾提前校验以标记类别，
+// 校验通过时 cobra 随后的校验同样会通过。
+func typeUsageErrors(cmd *spf13cobra.Command) {
+	if validate := cmd.Args; validate != nil {
+		cmd.Args = func(c *spf13cobra.Command, args []string) error {
+			return usageError(validate(c, args))
+		}
+	}
+
+	preRun, preRunE := cmd.PreRun, cmd.PreRunE
+	cmd.PreRun = nil
+	cmd.PreRunE = func(c *spf13cobra.Command, args []string) error {
+		if preRunE != nil {
+			if err := preRunE(c, args); err != nil {
+				return err
+			}
+		} else if preRun != nil {
+			preRun(c, args)
+		}
+		if err := c.ValidateRequiredFlags(); err != nil {
+			return usageError(err)
+		}
+		return usageError(c.ValidateFlagGroups())
+	}
+}
+
+// RenderError 使用主题渲染错误信息
+func RenderError(err error, theme *TreeTheme) string {
+	return renderError(err, theme, nil)
+}
+
+// renderError 使用主题和翻译渲染错误信息
+func renderError(err error, theme *TreeTheme, catalog *Catalog) string {
+	if theme == nil {
+		theme = DefaultTreeTheme()
+	}
+
+	var verr *ValidationError
+	if errors.As(err, &verr) {
+		return verr.Render(theme)
+	}
+
+	e := AsError(err)
+	var builder strings.Builder
+	builder.WriteString(renderLines(theme.ErrorStyle, "✗ "+catalog.message(MsgError)+": "+strings.TrimRight(e.Error(), "\n")))
+	if e.Hint != "" {
+		builder.WriteString("\n")
+		builder.WriteString(theme.DescriptionStyle.Render("  " + catalog.message(MsgErrorHint) + ": " + e.Hint))
+	}
+	return builder.String()
+}
+
+// renderLines 逐行渲染多行文本，避免 lipgloss 将每行补齐到相同宽度
+func renderLines(style lipgloss.Style, text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = style.Render(line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderUsageSnippet 渲染失败命令的简短用法说明
+func renderUsageSnippet(cmd *spf13cobra.Command, theme *TreeTheme, catalog *Catalog) string {
+	var builder strings.Builder
+	builder.WriteString(theme.BranchStyle.Render(catalog.message(MsgHelpUsage)))
+	builder.WriteString("\n")
+	builder.WriteString(theme.LeafStyle.Render("  " + cmd.UseLine()))
+	if cmd.HasAvailableSubCommands() {
+		builder.WriteString("\n")
+		builder.WriteString(theme.LeafStyle.Render("  " + cmd.CommandPath() + " [command]"))
+	}
+	builder.WriteString("\n\n")
+	builder.WriteString(theme.LineStyle.Render(fmt.Sprintf(catalog.message(MsgErrorMore), cmd.CommandPath())))
+	return builder.String()
+}
+
+// reportError 输出带主题的错误信息，用法错误额外输出失败命令的用法
+func (c *Command) reportError(cmd *spf13cobra.Command, err error, showUsage bool) {
+	if cmd == nil {
+		cmd = c.Command
+	}
+	config := c.commandFor(cmd).getTreeConfig()
+
+	cmd.PrintErrln(renderError(err, config.Theme, config.Catalog))
+	if showUsage && AsError(err).Category == CategoryUsage {
+		cmd.PrintErrln()
+		cmd.PrintErrln(renderUsageSnippet(cmd, config.Theme, config.Catalog))
+	}
+}
+
+// ExecuteAndExit 执行命令（含信号处理）并以错误对应的退出码退出进程
+//
+// 适合作为 main 函数的最后一行：
+//
+//	func main() {
+//	    rootCmd.ExecuteAndExit()
+//	}
+func (c *Command) ExecuteAndExit() {
+	err := c.ExecuteContext(context.Background())
+	os.Exit(ExitCode(err))
+}
@@ -0,0 +1,56 @@
+package cobra
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	spf13cobra "github.com/spf13/cobra"
+)
+
+func TestUsageErrorsTypedAtSource(t *testing.T) {
+	t.Setenv("COBRA_TREE", "")
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"unknown command", []string{"nope"}},
+		{"unknown flag", []string{"server", "--nope"}},
+		{"missing flag value", []string{"server", "--port"}},
+		{"invalid flag value", []string{"server", "--port", "abc"}},
+		{"args validator", []string{"server", "extra"}},
+		{"required flag", []string{"deploy"}},
+		{"flag group", []string{"deploy", "--env", "prod", "--json", "--yaml"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := NewCommand("usagetest")
+			server := NewCommand("server",
+				WithArgs(spf13cobra.NoArgs),
+				WithRun(func(cmd *Command, args []string) {}),
+			)
+			server.Flags().Int("port", 8080, "port")
+			deploy := NewCommand("deploy", WithRun(func(cmd *Command, args []string) {}))
+			deploy.Flags().String("env", "", "environment")
+			deploy.Flags().Bool("json", false, "json")
+			deploy.Flags().Bool("yaml", false, "yaml")
+			_ = deploy.MarkFlagRequired("env")
+			deploy.MarkFlagsMutuallyExclusive("json", "yaml")
+			root.AddCommand(server, deploy)
+
+			root.SetOut(&bytes.Buffer{})
+			root.SetErr(&bytes.Buffer{})
+			root.SetArgs(tt.args)
+
+			err := root.Execute()
+			var e *Error
+			if !errors.As(err, &e) || e.Category != CategoryUsage {
+				t.Fatalf("Execute(%q) = %#v, want a usage *Error", tt.args, err)
+			}
+			if got := ExitCode(err); got != ExitCodeUsage {
+				t.Errorf("ExitCode() = %d, want %d", got, ExitCodeUsage)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package cobra
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	spf13cobra "github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// ValueSource flag 取值来源
+type ValueSource string
+
+const (
+	// SourceDefault 使用 flag 默认值
+	SourceDefault ValueSource = "default"
+	// SourceFlag 来自命令行参数
+	SourceFlag ValueSource = "flag"
+	// SourceEnv 来自环境变量
+	SourceEnv ValueSource = "env"
+	// SourceConfig 来自配置文件
+	SourceConfig ValueSource = "config"
+)
+
+// FlagOrigin 记录一个 flag 的最终取值及其来源
+type FlagOrigin struct {
+	Flag   string      // flag 名称
+	Value  string      // 最终取值
+	Source ValueSource // 取值来源
+	Detail string      // 来源细节，如环境变量名、配置文件路径
+}
+
+// valueLayer flag 取值层（环境变量、配置文件等）
+type valueLayer interface {
+	// source 返回该层对应的取值来源
+	source() ValueSource
+	// lookup 查找 flag 在该层的取值，detail 用于说明具体来源
+	lookup(cmd *spf13cobra.Command, flag *pflag.Flag) (value string, detail string, ok bool)
+}
+
+// flagResolver 按优先级依次应用各取值层
+type flagResolver struct {
+	cmd     *spf13cobra.Command
+	origins map[string]*FlagOrigin
+	order   []string
+	errs    []string
+}
+
+// newFlagResolver 创建取值解析器，命令行已设置的 flag 标记为 SourceFlag
+func newFlagResolver(cmd *spf13cobra.Command) *flagResolver {
+	r := &flagResolver{
+		cmd:     cmd,
+		origins: make(map[string]*FlagOrigin),
+	}
+
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		if skipValueBinding(flag) {
+			return
+		}
+		origin := &FlagOrigin{Flag: flag.Name, Source: SourceDefault}
+		if flag.Changed {
+			origin.Source = SourceFlag
+		}
+		r.origins[flag.Name] = origin
+		r.order = append(r.order, flag.Name)
+	})
+
+	return r
+}
+
+// apply 对仍为默认值的 flag 应用一个取值层
+func (r *flagResolver) apply(layer valueLayer) {
+	for _, name := range r.order {
+		origin := r.origins[name]
+		if origin.Source != SourceDefault {
+			continue
+		}
+
+		flag := r.cmd.Flags().Lookup(name)
+		value, detail, ok := layer.lookup(r.cmd, flag)
+		if !ok {
+			continue
+		}
+
+		if err := r.cmd.Flags().Set(name, value); err != nil {
+			r.errs = append(r.errs, fmt.Sprintf("invalid value %q for --%s from %s: %v", value, name, detail, err))
+			continue
+		}
+		origin.Source = layer.source()
+		origin.Detail = detail
+	}
+}
+
+// result 返回每个 flag 的最终取值与来源
+func (r *flagResolver) result() ([]FlagOrigin, error) {
+	origins := make([]FlagOrigin, 0, len(r.order))
+	for _, name := range r.order {
+		origin := *r.origins[name]
+		origin.Value = r.cmd.Flags().Lookup(name).Value.String()
+		origins = append(origins, origin)
+	}
+
+	if len(r.errs) > 0 {
+		return origins, fmt.Errorf("%s", strings.Join(r.errs, "\n"))
+	}
+	return origins, nil
+}
+
+// frameworkFlagAnnotation 标记 cobrax 添加的功能 flag（--config、--output 等）的 flag 注解键
+const frameworkFlagAnnotation = "cobrax_framework"
+
+// markFrameworkFlags 将 flags 标记为 cobrax 的功能 flag，不参与环境变量、配置文件等绑定
+func markFrameworkFlags(flags *pflag.FlagSet, names ...string) {
+	for _, name := range names {
+		_ = flags.SetAnnotation(name, frameworkFlagAnnotation, []string{"true"})
+	}
+}
+
+// skipValueBinding 判断 flag 是否不参与环境变量、配置文件等绑定：
+// --tree 系列、--help、--version 以及其它 cobrax 添加的功能 flag
+func skipValueBinding(flag *pflag.Flag) bool {
+	return isBuiltinFlag(flag.Name) || flag.Name == "help" || flag.Name == "version" ||
+		len(flag.Annotations[frameworkFlagAnnotation]) > 0
+}
+
+// controlFlagValue 返回 --config、--profile 等控制类 flag 的取值：命令行 > <PREFIX>_<FLAG> 环境变量 > 默认值
+//
+// 它们决定从哪里读取其它 flag 的取值，因此不经过取值层，也不能来自配置文件或 profile。
+func (c *Command) controlFlagValue(cmd *spf13cobra.Command, name string) string {
+	flag := cmd.Flags().Lookup(name)
+	if flag == nil {
+		return ""
+	}
+	if !flag.Changed && c.envPrefix != "" {
+		for _, env := range envVarNames(c.envPrefix, c.Command, name) {
+			if value, ok := os.LookupEnv(env); ok {
+				return value
+			}
+		}
+	}
+	return flag.Value.String()
+}
+
+// valueDebugEnabled 判断是否输出取值来源调试信息
+func valueDebugEnabled() bool {
+	return os.Getenv("COBRA_ENV_DEBUG") == "true"
+}
+
+// printFlagOrigins 输出每个 flag 的取值来源
+func printFlagOrigins(cmd *spf13cobra.Command, origins []FlagOrigin, theme *TreeTheme) {
+	for _, origin := range origins {
+		source := string(origin.Source)
+		if origin.Detail != "" {
+			source += ": " + origin.Detail
+		}
+		line := theme.FlagStyle.Render("--"+origin.Flag) + " = " + origin.Value + " " +
+			theme.LineStyle.Render("("+source+")")
+		cmd.PrintErrln(line)
+	}
+}
@@ -0,0 +1,124 @@
+package cobra
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRequiredFlagFromValueLayers(t *testing.T) {
+	t.Setenv("COBRA_TREE", "")
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "app.json")
+	if err := os.WriteFile(configPath, []byte(`{"name": "from-config"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	profilesPath := filepath.Join(dir, "profiles.json")
+	if err := os.WriteFile(profilesPath, []byte(`{"profiles": {"prod": {"name": "from-profile"}}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		env  map[string]string
+		args []string
+		want string
+	}{
+		{"env", map[string]string{"APP_NAME": "from-env"}, nil, "from-env"},
+		{"config", nil, []string{"--config", configPath}, "from-config"},
+		{"config from env", map[string]string{"APP_CONFIG": configPath}, nil, "from-config"},
+		{"profile", nil, []string{"--profile", "prod"}, "from-profile"},
+		{"profile from env", map[string]string{"APP_PROFILE": "prod"}, nil, "from-profile"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			var got string
+			root := NewCommand("app",
+				WithEnvPrefix("APP"),
+				WithConfigFile("app", t.TempDir()), // 只使用 --config 指定的配置文件
+				WithProfilesFile(profilesPath),
+				WithFlags(func(flags *FlagSet) { flags.String("name", "", "Name") }),
+				WithRequiredFlags("name"),
+				WithRun(func(cmd *Command, args []string) { got, _ = cmd.Flags().GetString("name") }),
+			)
+			root.SetOut(&bytes.Buffer{})
+			root.SetErr(&bytes.Buffer{})
+			root.SetArgs(tt.args)
+			if err := root.Execute(); err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("--name = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFrameworkFlagsNotBound(t *testing.T) {
+	t.Setenv("COBRA_TREE", "")
+	t.Setenv("APP_OUTPUT", "json")
+	t.Setenv("APP_TIMEOUT", "1s")
+
+	root := NewCommand("app",
+		WithEnvPrefix("APP"),
+		WithConfigFile("app", t.TempDir()),
+		WithProfiles(),
+		WithTimeout(0),
+		WithOutput(OutputTable),
+		WithLanguages(nil),
+		WithVersion("v1.0.0"),
+		WithFlags(func(flags *FlagSet) { flags.String("name", "", "Name") }),
+		WithRun(func(cmd *Command, args []string) {}),
+	)
+	var stdout bytes.Buffer
+	root.SetOut(&stdout)
+	root.SetArgs([]string{"config", "dump"})
+	if err := root.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	dump := stdout.String()
+	if !strings.Contains(dump, "--name") {
+		t.Errorf("config dump is missing --name:\n%s", dump)
+	}
+	for _, name := range []string{"output", "columns", "sort-by", "no-headers", "lang", "timeout", "profile", "config", "version", "help", "tree"} {
+		if strings.Contains(dump, "--"+name+" ") {
+			t.Errorf("config dump lists framework flag --%s:\n%s", name, dump)
+		}
+	}
+}
+
+func TestEnvUsagesMatchLookup(t *testing.T) {
+	t.Setenv("COBRA_TREE", "")
+
+	server := NewCommand("server", WithRun(func(cmd *Command, args []string) {}))
+	root := NewCommand("app",
+		WithEnvPrefix("APP"),
+		WithConfigFile("app", t.TempDir()),
+		WithPersistentFlags(func(flags *FlagSet) { flags.Int("port", 8080, "Port") }),
+		WithSubcommands(server),
+	)
+	var out bytes.Buffer
+	root.SetOut(&out)
+	root.SetErr(&bytes.Buffer{})
+	root.SetArgs([]string{"server", "--help"})
+	if err := root.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 继承的 --port 按子命令路径查找，--config 只读取 APP_CONFIG
+	for _, want := range []string{"Port [env: APP_SERVER_PORT, APP_PORT]", "[env: APP_CONFIG]"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("help missing %q:\n%s", want, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "APP_SERVER_CONFIG") {
+		t.Errorf("help lists a command-path env for --config:\n%s", out.String())
+	}
+}
@@ -0,0 +1,384 @@
+package cobra
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	spf13cobra "github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// TreeSortMode 树形视图中子命令的排序方式
+type TreeSortMode string
+
+const (
+	// TreeSortGroup 按分组声明顺序分节，组内按名称排序（默认）
+	TreeSortGroup TreeSortMode = "group"
+	// TreeSortName 按名称排序，不分节
+	TreeSortName TreeSortMode = "name"
+	// TreeSortRegistration 按注册顺序排列，不分节
+	TreeSortRegistration TreeSortMode = "registration"
+)
+
+// TreeFormat 命令树的输出格式
+type TreeFormat string
+
+const (
+	// TreeFormatText 带主题的文本（默认）
+	TreeFormatText TreeFormat = "text"
+	// TreeFormatJSON JSON
+	TreeFormatJSON TreeFormat = "json"
+	// TreeFormatMarkdown Markdown
+	TreeFormatMarkdown TreeFormat = "markdown"
+)
+
+// TreeGroup 命令分组
+type TreeGroup struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+// WithGroup 在命令上声明子命令分组
+//
+// 子命令通过 WithGroupID 加入分组，分组会在帮助信息和树形视图中按声明顺序分节显示：
+//
+//	rootCmd := cobra.NewCommand("myapp",
+//	    cobra.WithGroup("core", "Core Commands"),
+//	    cobra.WithGroup("manage", "Management Commands"),
+//	)
+//	serverCmd := cobra.NewCommand("server", cobra.WithGroupID("core"))
+func WithGroup(id, title string) CommandOption {
+	return func(c *Command) {
+		if !c.ContainsGroup(id) {
+			c.AddGroup(&spf13cobra.Group{ID: id, Title: title})
+		}
+	}
+}
+
+// WithGroupID 设置命令所属的分组，分组必须在父命令上通过 WithGroup 声明
+func WithGroupID(id string) CommandOption {
+	return func(c *Command) {
+		c.GroupID = id
+	}
+}
+
+// parseTreeSortMode 解析排序方式，无法识别时返回 TreeSortGroup 和用法错误
+func parseTreeSortMode(s string) (TreeSortMode, error) {
+	switch mode := TreeSortMode(s); mode {
+	case TreeSortGroup, TreeSortName, TreeSortRegistration:
+		return mode, nil
+	default:
+		return TreeSortGroup, NewUsageError("unsupported tree sort mode %q", s).
+			WithHint(fmt.Sprintf("use one of: %s, %s, %s", TreeSortGroup, TreeSortName, TreeSortRegistration))
+	}
+}
+
+// parseTreeFormat 解析输出格式，无法识别时返回 TreeFormatText 和用法错误
+func parseTreeFormat(s string) (TreeFormat, error) {
+	switch format := TreeFormat(s); format {
+	case TreeFormatText, TreeFormatJSON, TreeFormatMarkdown:
+		return format, nil
+	default:
+		return TreeFormatText, NewUsageError("unsupported tree format %q", s).
+			WithHint(fmt.Sprintf("use one of: %s, %s, %s", TreeFormatText, TreeFormatJSON, TreeFormatMarkdown))
+	}
+}
+
+// validateTreeFlags 校验 --tree-sort 和 --tree-format 的取值
+func validateTreeFlags(flags *pflag.FlagSet) error {
+	if sortMode, err := flags.GetString("tree-sort"); err == nil {
+		if _, err := parseTreeSortMode(sortMode); err != nil {
+			return err
+		}
+	}
+	if format, err := flags.GetString("tree-format"); err == nil {
+		if _, err := parseTreeFormat(format); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordRegistration 记录子命令的注册顺序（已记录的保持不变）
+func (c *Command) recordRegistration(cmds ...*spf13cobra.Command) {
+	for _, cmd := range cmds {
+		if c.registrationIndex(cmd) < 0 {
+			c.registered = append(c.registered, cmd)
+		}
+	}
+}
+
+// registrationIndex 返回子命令的注册序号，未记录的返回 -1
+func (c *Command) registrationIndex(cmd *spf13cobra.Command) int {
+	for i, registered := range c.registered {
+		if registered == cmd {
+			return i
+		}
+	}
+	return -1
+}
+
+// replaceRegistration 让替换后的子命令沿用原命令的注册序号
+func (c *Command) replaceRegistration(from, to *spf13cobra.Command) {
+	if i := c.registrationIndex(from); i >= 0 {
+		c.registered[i] = to
+	}
+}
+
+// commandsInRegistrationOrder 返回按注册顺序排列的子命令（未记录的保持原有顺序排在最后）
+func (c *Command) commandsInRegistrationOrder(cmds []*spf13cobra.Command) []*spf13cobra.Command {
+	index := func(cmd *spf13cobra.Command) int {
+		if i := c.registrationIndex(cmd); i >= 0 {
+			return i
+		}
+		return len(c.registered)
+	}
+	ordered := append([]*spf13cobra.Command{}, cmds...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return index(ordered[i]) < index(ordered[j])
+	})
+	return ordered
+}
+
+// treeGroups 返回命令声明的分组
+func treeGroups(cmd *spf13cobra.Command) []TreeGroup {
+	var groups []TreeGroup
+	for _, group := range cmd.Groups() {
+		groups = append(groups, TreeGroup{ID: group.ID, Title: group.Title})
+	}
+	return groups
+}
+
+// sortTree 按排序方式复制并重排命令树（快照中的节点不会被修改）
+func sortTree(node *TreeDisplayNode, mode TreeSortMode) *TreeDisplayNode {
+	sorted := *node
+	sorted.Children = make([]*TreeDisplayNode, 0, len(node.Children))
+	for _, child := range node.Children {
+		sorted.Children = append(sorted.Children, sortTree(child, mode))
+	}
+
+	switch mode {
+	case TreeSortName:
+		sort.SliceStable(sorted.Children, func(i, j int) bool {
+			return sorted.Children[i].Name < sorted.Children[j].Name
+		})
+	case TreeSortGroup:
+		rank := node.groupRanks()
+		sort.SliceStable(sorted.Children, func(i, j int) bool {
+			a, b := sorted.Children[i], sorted.Children[j]
+			if rank[a.Group] != rank[b.Group] {
+				return rank[a.Group] < rank[b.Group]
+			}
+			return a.Name < b.Name
+		})
+	}
+	return &sorted
+}
+
+// groupRanks 返回分组 ID -> 声明顺序，未分组（或分组未声明）的命令排在最后
+func (n *TreeDisplayNode) groupRanks() map[string]int {
+	rank := make(map[string]int, len(n.Groups))
+	for _, child := range n.Children {
+		rank[child.Group] = len(n.Groups)
+	}
+	for i, group := range n.Groups {
+		rank[group.ID] = i
+	}
+	return rank
+}
+
+// groupTitle 返回子命令所在分节的标题，节点没有声明分组时返回空字符串
+func (n *TreeDisplayNode) groupTitle(child *TreeDisplayNode, catalog *Catalog) string {
+	if len(n.Groups) == 0 {
+		return ""
+	}
+	for _, group := range n.Groups {
+		if group.ID == child.Group {
+			return group.Title
+		}
+	}
+	return catalog.message(MsgAdditionalCommands)
+}
+
+// groupHeadings 按分节顺序返回每个子命令前需要输出的分节标题（不需要时为空字符串）
+func groupHeadings(node *TreeDisplayNode, config *TreeConfig) []string {
+	headings := make([]string, len(node.Children))
+	if config.Sort != TreeSortGroup {
+		return headings
+	}
+	previous := ""
+	for i, child := range node.Children {
+		if title := node.groupTitle(child, config.Catalog); title != previous {
+			headings[i] = title
+			previous = title
+		}
+	}
+	return headings
+}
+
+// treeJSONNode JSON 导出的节点，分组模式下子命令按分组组织
+type treeJSONNode struct {
+	Name        string            `json:"name"`
+	Path        string            `json:"path"`
+	Description string            `json:"description,omitempty"`
+	IsRunnable  bool              `json:"runnable,omitempty"`
+	Group       string            `json:"group,omitempty"`
+	Flags       []FlagDisplayInfo `json:"flags,omitempty"`
+	Groups      []treeJSONGroup   `json:"groups,omitempty"`
+	Children    []*treeJSONNode   `json:"children,omitempty"`
+}
+
+// treeJSONGroup JSON 导出的分组
+type treeJSONGroup struct {
+	ID       string          `json:"id,omitempty"`
+	Title    string          `json:"title"`
+	Children []*treeJSONNode `json:"children"`
+}
+
+// RenderTreeJSON 将命令树导出为 JSON
+//
+// 按 config.Sort 排序；分组模式下声明了分组的命令的子命令放在 groups 中，其余放在 children 中。
+func RenderTreeJSON(tree *TreeDisplayNode, config *TreeConfig) (string, error) {
+	config = withTreeDefaults(config)
+	data, err := json.MarshalIndent(toTreeJSON(sortTree(tree, config.Sort), config), "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// toTreeJSON 转换为 JSON 导出节点
+func toTreeJSON(node *TreeDisplayNode, config *TreeConfig) *treeJSONNode {
+	out := &treeJSONNode{
+		Name:        node.Name,
+		Path:        node.Path,
+		Description: node.Description,
+		IsRunnable:  node.IsRunnable,
+		Group:       node.Group,
+	}
+	if config.ShowFlags {
+		out.Flags = node.Flags
+	}
+
+	if config.Sort != TreeSortGroup || len(node.Groups) == 0 {
+		for _, child := range node.Children {
+			out.Children = append(out.Children, toTreeJSON(child, config))
+		}
+		return out
+	}
+
+	for _, child := range node.Children {
+		title := node.groupTitle(child, config.Catalog)
+		if n := len(out.Groups); n == 0 || out.Groups[n-1].Title != title {
+			group := treeJSONGroup{Title: title}
+			if title != config.Catalog.message(MsgAdditionalCommands) {
+				group.ID = child.Group
+			}
+			out.Groups = append(out.Groups, group)
+		}
+		group := &out.Groups[len(out.Groups)-1]
+		group.Children = append(group.Children, toTreeJSON(child, config))
+	}
+	return out
+}
+
+// RenderTreeMarkdown 将命令树导出为 Markdown
+//
+// 根命令为一级标题；分组模式下根命令的分组为二级标题，更深层的分组为加粗的列表项。
+func RenderTreeMarkdown(tree *TreeDisplayNode, config *TreeConfig) string {
+	config = withTreeDefaults(config)
+	tree = sortTree(tree, config.Sort)
+
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "# %s\n", tree.Path)
+	if tree.Description != "" {
+		fmt.Fprintf(&builder, "\n%s\n", tree.Description)
+	}
+	if config.ShowFlags {
+		builder.WriteString("\n")
+		writeMarkdownFlags(&builder, tree.Flags, "")
+	}
+
+	headings := groupHeadings(tree, config)
+	if len(tree.Children) > 0 && headings[0] == "" {
+		builder.WriteString("\n")
+	}
+	for i, child := range tree.Children {
+		if headings[i] != "" {
+			fmt.Fprintf(&builder, "\n## %s\n\n", headings[i])
+		}
+		writeMarkdownNode(&builder, child, "", config)
+	}
+	return builder.String()
+}
+
+// writeMarkdownNode 以列表项输出命令及其子命令
+func writeMarkdownNode(builder *strings.Builder, node *TreeDisplayNode, indent string, config *TreeConfig) {
+	fmt.Fprintf(builder, "%s- `%s`", indent, node.Path)
+	if node.Description != "" {
+		fmt.Fprintf(builder, " — %s", node.Description)
+	}
+	builder.WriteString("\n")
+
+	childIndent := indent + "  "
+	if config.ShowFlags {
+		writeMarkdownFlags(builder, node.Flags, childIndent)
+	}
+
+	headings := groupHeadings(node, config)
+	for i, child := range node.Children {
+		if headings[i] != "" {
+			fmt.Fprintf(builder, "%s- **%s**\n", childIndent, headings[i])
+		}
+		writeMarkdownNode(builder, child, childIndent, config)
+	}
+}
+
+// writeMarkdownFlags 以列表项输出 flags
+func writeMarkdownFlags(builder *strings.Builder, flags []FlagDisplayInfo, indent string) {
+	for _, flag := range flags {
+		name := "`--" + flag.Name + "`"
+		if flag.ShortName != "" {
+			name = "`-" + flag.ShortName + "`, " + name
+		}
+		fmt.Fprintf(builder, "%s- %s", indent, name)
+		if flag.Description != "" {
+			fmt.Fprintf(builder, ": %s", flag.Description)
+		}
+		if flag.DefaultValue != "" && flag.DefaultValue != "[]" && !(flag.Type == "bool" && flag.DefaultValue == "false") {
+			fmt.Fprintf(builder, " (default: `%s`)", flag.DefaultValue)
+		}
+		builder.WriteString("\n")
+	}
+}
+
+// withTreeDefaults 复制配置并补全未设置的主题和排序方式
+func withTreeDefaults(config *TreeConfig) *TreeConfig {
+	if config == nil {
+		config = &TreeConfig{ShowLong: true}
+	} else {
+		copied := *config
+		config = &copied
+	}
+	if config.Theme == nil {
+		config.Theme = DefaultTreeTheme()
+	}
+	if config.Sort == "" {
+		config.Sort = TreeSortGroup
+	}
+	return config
+}
+
+// renderTreeOutput 按 config.Format 渲染命令树
+func renderTreeOutput(tree *TreeDisplayNode, config *TreeConfig) (string, error) {
+	switch config.Format {
+	case TreeFormatJSON:
+		return RenderTreeJSON(tree, config)
+	case TreeFormatMarkdown:
+		return RenderTreeMarkdown(tree, config), nil
+	default:
+		return RenderFlatTree(tree, config), nil
+	}
+}
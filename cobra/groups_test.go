@@ -0,0 +1,41 @@
+package cobra
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestInvalidTreeFlags(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		hint string
+	}{
+		{"sort on root", []string{"--tree", "--tree-sort", "size"}, "group, name, registration"},
+		{"format on root", []string{"--tree", "--tree-format", "yaml"}, "text, json, markdown"},
+		{"format on runnable command", []string{"server", "--tree", "--tree-format", "yaml"}, "text, json, markdown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := NewCommand("treetest")
+			root.AddCommand(NewCommand("server", WithRun(func(cmd *Command, args []string) {})))
+			var stdout, stderr bytes.Buffer
+			root.SetOut(&stdout)
+			root.SetErr(&stderr)
+			root.SetArgs(tt.args)
+
+			err := root.Execute()
+			if got := ExitCode(err); got != ExitCodeUsage {
+				t.Fatalf("ExitCode(%v) = %d, want %d", err, got, ExitCodeUsage)
+			}
+			if !strings.Contains(stderr.String(), tt.hint) {
+				t.Errorf("stderr %q does not list %q", stderr.String(), tt.hint)
+			}
+			if stdout.Len() > 0 {
+				t.Errorf("tree rendered despite invalid flag: %q", stdout.String())
+			}
+		})
+	}
+}
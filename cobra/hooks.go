@@ -0,0 +1,114 @@
+package cobra
+
+import (
+	spf13cobra "github.com/spf13/cobra"
+)
+
+// hookFunc cobrax 内部的持久化钩子（树形视图、flag 取值解析等）
+type hookFunc func(cmd *spf13cobra.Command, args []string) error
+
+// persistentHooks 用户在某个命令上定义的持久化钩子
+type persistentHooks struct {
+	preRun   func(*spf13cobra.Command, []string)
+	preRunE  func(*spf13cobra.Command, []string) error
+	postRun  func(*spf13cobra.Command, []string)
+	postRunE func(*spf13cobra.Command, []string) error
+}
+
+// hookRegistry 记录被接管的用户持久化钩子，由接管钩子的根命令（或 Enhance）持有
+type hookRegistry map[*spf13cobra.Command]*persistentHooks
+
+// install 接管命令树中所有命令的持久化钩子
+//
+// cobra 默认只执行离当前命令最近的 PersistentPreRun(E)，子命令定义自己的钩子后，
+// 根命令上的钩子（包括 cobrax 的树形视图处理）就不会再执行。这里把用户的钩子保存下来，
+// 在每个命令上安装统一的分发器，保证执行顺序为：
+//
+//	cobrax 内部钩子 -> 根命令 PersistentPreRun -> ... -> 当前命令 PersistentPreRun
+//	当前命令 PersistentPostRun -> ... -> 根命令 PersistentPostRun
+func (r hookRegistry) install(cmd *spf13cobra.Command, internal hookFunc) {
+	if _, done := r[cmd]; !done {
+		owner := cmd
+		r[cmd] = &persistentHooks{
+			preRun:   cmd.PersistentPreRun,
+			preRunE:  cmd.PersistentPreRunE,
+			postRun:  cmd.PersistentPostRun,
+			postRunE: cmd.PersistentPostRunE,
+		}
+
+		cmd.PersistentPreRun = nil
+		cmd.PersistentPostRun = nil
+		cmd.PersistentPreRunE = func(c *spf13cobra.Command, args []string) error {
+			return r.runPre(owner, c, args, internal)
+		}
+		cmd.PersistentPostRunE = func(c *spf13cobra.Command, args []string) error {
+			return r.runPost(owner, c, args)
+		}
+	}
+
+	for _, child := range cmd.Commands() {
+		r.install(child, internal)
+	}
+}
+
+// hookChain 返回需要执行钩子的命令（从根命令到当前命令）
+//
+// 若开启了 spf13cobra.EnableTraverseRunHooks，cobra 自己会逐级调用每个分发器，
+// 此时每个分发器只负责自己所在的命令。
+func hookChain(owner, cmd *spf13cobra.Command) []*spf13cobra.Command {
+	if spf13cobra.EnableTraverseRunHooks {
+		return []*spf13cobra.Command{owner}
+	}
+
+	var chain []*spf13cobra.Command
+	for p := cmd; p != nil; p = p.Parent() {
+		chain = append([]*spf13cobra.Command{p}, chain...)
+	}
+	return chain
+}
+
+// runPre 依次执行 cobrax 内部钩子和从根到当前命令的用户 PersistentPreRun
+func (r hookRegistry) runPre(owner, cmd *spf13cobra.Command, args []string, internal hookFunc) error {
+	chain := hookChain(owner, cmd)
+
+	if internal != nil && !chain[0].HasParent() {
+		if err := internal(cmd, args); err != nil {
+			return err
+		}
+	}
+
+	for _, p := range chain {
+		hooks, ok := r[p]
+		if !ok {
+			continue
+		}
+		if hooks.preRunE != nil {
+			if err := hooks.preRunE(cmd, args); err != nil {
+				return err
+			}
+		} else if hooks.preRun != nil {
+			hooks.preRun(cmd, args)
+		}
+	}
+	return nil
+}
+
+// runPost 依次执行从当前命令到根命令的用户 PersistentPostRun
+func (r hookRegistry) runPost(owner, cmd *spf13cobra.Command, args []string) error {
+	chain := hookChain(owner, cmd)
+
+	for i := len(chain) - 1; i >= 0; i-- {
+		hooks, ok := r[chain[i]]
+		if !ok {
+			continue
+		}
+		if hooks.postRunE != nil {
+			if err := hooks.postRunE(cmd, args); err != nil {
+				return err
+			}
+		} else if hooks.postRun != nil {
+			hooks.postRun(cmd, args)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package cobra
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	spf13cobra "github.com/spf13/cobra"
+)
+
+func TestPersistentHookOrder(t *testing.T) {
+	t.Setenv("COBRA_TREE", "")
+
+	var calls []string
+	record := func(name string) func(*Command, []string) error {
+		return func(cmd *Command, args []string) error {
+			calls = append(calls, name)
+			return nil
+		}
+	}
+
+	leaf := NewCommand("leaf", WithRun(func(cmd *Command, args []string) {
+		calls = append(calls, "leaf")
+	}))
+	sub := NewCommand("sub",
+		WithPersistentPreRun(record("sub-pre")),
+		WithPersistentPostRun(record("sub-post")),
+		WithSubcommands(leaf),
+	)
+	root := NewCommand("hooktest",
+		WithPersistentPreRun(record("root-pre")),
+		WithPersistentPostRun(record("root-post")),
+		WithSubcommands(sub),
+	)
+	root.SetOut(&bytes.Buffer{})
+	root.SetErr(&bytes.Buffer{})
+	root.SetArgs([]string{"sub", "leaf"})
+
+	if err := root.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"root-pre", "sub-pre", "leaf", "sub-post", "root-post"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+}
+
+func TestUserPersistentPreRunKeepsTreeFlag(t *testing.T) {
+	t.Setenv("COBRA_TREE", "")
+
+	var calls []string
+	leaf := NewCommand("leaf", WithRun(func(cmd *Command, args []string) {
+		calls = append(calls, "leaf")
+	}))
+	sub := NewCommand("sub", WithSubcommands(leaf))
+	// 直接设置 spf13/cobra 的字段，不经过 cobrax 的选项
+	sub.PersistentPreRunE = func(cmd *spf13cobra.Command, args []string) error {
+		calls = append(calls, "sub-pre")
+		return nil
+	}
+	root := NewCommand("hooktest", WithSubcommands(sub))
+
+	var out bytes.Buffer
+	root.SetOut(&out)
+	root.SetErr(&bytes.Buffer{})
+
+	root.SetArgs([]string{"sub", "leaf"})
+	if err := root.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"sub-pre", "leaf"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+
+	// 子命令自定义钩子后，根命令的 --tree 处理仍然先于它执行
+	calls = nil
+	root.SetArgs([]string{"sub", "leaf", "--tree"})
+	if err := root.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 0 {
+		t.Errorf("calls = %v after --tree, want none", calls)
+	}
+	if !strings.Contains(out.String(), "leaf") {
+		t.Errorf("tree not shown: %q", out.String())
+	}
+}
@@ -0,0 +1,525 @@
+package cobra
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	spf13cobra "github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// langFlagName 选择界面语言的 flag 名称
+const langFlagName = "lang"
+
+// cobrax 界面文字的消息键，可在 Catalog.Messages 中翻译
+const (
+	MsgTreeTitle          = "tree.title"      // 命令树标题，%d 为命令数
+	MsgTreeProfile        = "tree.profile"    // 当前 profile 标识，%s 为 profile 名称
+	MsgAdditionalCommands = "tree.additional" // 未分组命令的分节标题
+
+	MsgFlagTree       = "flag.tree"
+	MsgFlagTreeTheme  = "flag.tree-theme"
+	MsgFlagTreeFlags  = "flag.tree-flags"
+	MsgFlagTreeLong   = "flag.tree-long"
+	MsgFlagTreeSort   = "flag.tree-sort"
+	MsgFlagTreeFormat = "flag.tree-format"
+	MsgFlagTreeWidth  = "flag.tree-width"
+	MsgFlagLang       = "flag.lang"
+	MsgFlagNoPager    = "flag.no-pager"
+	MsgFlagHelp       = "flag.help"    // %s 为命令名称
+	MsgFlagVersion    = "flag.version" // %s 为命令名称
+	MsgFlagConfig     = "flag.config"
+	MsgFlagProfile    = "flag.profile"
+	MsgFlagTimeout    = "flag.timeout"
+	MsgFlagOutput     = "flag.output"
+	MsgFlagColumns    = "flag.columns"
+	MsgFlagSortBy     = "flag.sort-by"
+	MsgFlagNoHeaders  = "flag.no-headers"
+
+	MsgHelpUsage              = "help.usage"
+	MsgHelpAliases            = "help.aliases"
+	MsgHelpExamples           = "help.examples"
+	MsgHelpAvailableCommands  = "help.commands"
+	MsgHelpAdditionalCommands = "help.additional"
+	MsgHelpFlags              = "help.flags"
+	MsgHelpGlobalFlags        = "help.global-flags"
+	MsgHelpTopics             = "help.topics"
+	MsgHelpMore               = "help.more" // %s 为命令路径
+
+	MsgError     = "error.title"
+	MsgErrorHint = "error.hint"
+	MsgErrorMore = "error.more" // %s 为命令路径
+
+	MsgCmdHelp            = "cmd.help"
+	MsgCmdCompletion      = "cmd.completion"
+	MsgCmdCompletionShell = "cmd.completion.shell" // %s 为 shell 名称
+	MsgCmdConfig          = "cmd.config"
+	MsgCmdConfigDump      = "cmd.config.dump"
+	MsgCmdConfigDumpLong  = "cmd.config.dump.long"
+	MsgCmdProfile         = "cmd.profile"
+	MsgCmdProfileList     = "cmd.profile.list"
+	MsgCmdProfileCreate   = "cmd.profile.create"
+	MsgCmdProfileShow     = "cmd.profile.show"
+	MsgCmdProfileDelete   = "cmd.profile.delete"
+	MsgFlagProfileSet     = "flag.profile.set"
+	MsgFlagProfileCommand = "flag.profile.command"
+	MsgFlagProfileForce   = "flag.profile.force"
+	MsgCmdVersion         = "cmd.version"
+	MsgFlagVersionOutput  = "flag.version.output"
+)
+
+// defaultMessages 界面文字的默认（英文）文本
+var defaultMessages = map[string]string{
+	MsgTreeTitle:          "Command Tree (%d commands)",
+	MsgTreeProfile:        "[profile: %s]",
+	MsgAdditionalCommands: "Additional Commands",
+
+	MsgFlagTree:       "Display command tree",
+	MsgFlagTreeTheme:  "Tree theme (default, dracula, nord, monokai, light)",
+	MsgFlagTreeFlags:  "Show flags in tree view",
+	MsgFlagTreeLong:   "Show long descriptions in tree view",
+	MsgFlagTreeSort:   "Tree sort order (group, name, registration)",
+	MsgFlagTreeFormat: "Tree output format (text, json, markdown)",
+	MsgFlagTreeWidth:  "Wrap tree output at this width (default: $COLUMNS or the terminal width)",
+	MsgFlagLang:       "Language of help and tree output (default: $LC_ALL, $LC_MESSAGES or $LANG)",
+	MsgFlagNoPager:    "Do not pipe long tree and help output through a pager",
+	MsgFlagHelp:       "help for %s",
+	MsgFlagVersion:    "version for %s",
+	MsgFlagConfig:     "Config file path",
+	MsgFlagProfile:    "Profile to load flag defaults from",
+	MsgFlagTimeout:    "Cancel the command after this duration (0 means no timeout)",
+	MsgFlagOutput:     "Output format (table, json, jsonl, yaml, template=<template>)",
+	MsgFlagColumns:    "Table columns to show, in order",
+	MsgFlagSortBy:     "Sort list output by this column (prefix with - for descending)",
+	MsgFlagNoHeaders:  "Omit the table header",
+
+	MsgHelpUsage:              "Usage:",
+	MsgHelpAliases:            "Aliases:",
+	MsgHelpExamples:           "Examples:",
+	MsgHelpAvailableCommands:  "Available Commands:",
+	MsgHelpAdditionalCommands: "Additional Commands:",
+	MsgHelpFlags:              "Flags:",
+	MsgHelpGlobalFlags:        "Global Flags:",
+	MsgHelpTopics:             "Additional help topics:",
+	MsgHelpMore:               `Use "%s [command] --help" for more information about a command.`,
+
+	MsgError:     "Error",
+	MsgErrorHint: "hint",
+	MsgErrorMore: "Run '%s --help' for more information.",
+
+	MsgCmdHelp:            "Help about any command",
+	MsgCmdCompletion:      "Generate the autocompletion script for the specified shell",
+	MsgCmdCompletionShell: "Generate the autocompletion script for %s",
+	MsgCmdConfig:          "Manage configuration",
+	MsgCmdConfigDump:      "Show effective flag values and their sources",
+	MsgCmdConfigDumpLong:  "Show the effective value of every flag of the given command and where it came from (flag, env, config or default).",
+	MsgCmdProfile:         "Manage named flag presets",
+	MsgCmdProfileList:     "List profiles",
+	MsgCmdProfileCreate:   "Create a profile",
+	MsgCmdProfileShow:     "Show a profile (default: the active one)",
+	MsgCmdProfileDelete:   "Delete a profile",
+	MsgFlagProfileSet:     "Flag default as flag=value (repeatable)",
+	MsgFlagProfileCommand: "Command path the values apply to (default: all commands)",
+	MsgFlagProfileForce:   "Overwrite an existing profile",
+	MsgCmdVersion:         "Show version and build information",
+	MsgFlagVersionOutput:  "Output format (text|json)",
+}
+
+// builtinCatalogs 内置的翻译，按语言标签
+var builtinCatalogs = map[string]*Catalog{
+	"zh": {
+		Messages: map[string]string{
+			MsgTreeTitle:          "命令树（共 %d 个命令）",
+			MsgAdditionalCommands: "其他命令",
+
+			MsgFlagTree:       "显示命令树",
+			MsgFlagTreeTheme:  "命令树主题（default, dracula, nord, monokai, light）",
+			MsgFlagTreeFlags:  "在命令树中显示 flags",
+			MsgFlagTreeLong:   "在命令树中显示命令描述",
+			MsgFlagTreeSort:   "命令树排序方式（group, name, registration）",
+			MsgFlagTreeFormat: "命令树输出格式（text, json, markdown）",
+			MsgFlagTreeWidth:  "命令树输出的换行宽度（默认为 $COLUMNS 或终端宽度）",
+			MsgFlagLang:       "帮助和命令树使用的语言（默认读取 $LC_ALL、$LC_MESSAGES 或 $LANG）",
+			MsgFlagNoPager:    "不使用分页器显示较长的命令树和帮助信息",
+			MsgFlagHelp:       "显示 %s 的帮助",
+			MsgFlagVersion:    "显示 %s 的版本",
+			MsgFlagConfig:     "配置文件路径",
+			MsgFlagProfile:    "加载 flag 默认值的 profile",
+			MsgFlagTimeout:    "超过该时长后取消命令（0 表示不限制）",
+			MsgFlagOutput:     "输出格式（table, json, jsonl, yaml, template=<模板>）",
+			MsgFlagColumns:    "表格中依次显示的列",
+			MsgFlagSortBy:     "列表按该列排序（以 - 开头时降序）",
+			MsgFlagNoHeaders:  "表格不输出表头",
+
+			MsgHelpUsage:              "用法：",
+			MsgHelpAliases:            "别名：",
+			MsgHelpExamples:           "示例：",
+			MsgHelpAvailableCommands:  "可用命令：",
+			MsgHelpAdditionalCommands: "其他命令：",
+			MsgHelpFlags:              "选项：",
+			MsgHelpGlobalFlags:        "全局选项：",
+			MsgHelpTopics:             "其他帮助主题：",
+			MsgHelpMore:               `使用 "%s [command] --help" 查看命令的详细信息。`,
+
+			MsgError:     "错误",
+			MsgErrorHint: "提示",
+			MsgErrorMore: "运行 '%s --help' 查看详细信息。",
+
+			MsgCmdHelp:            "显示命令的帮助",
+			MsgCmdCompletion:      "生成指定 shell 的自动补全脚本",
+			MsgCmdCompletionShell: "生成 %s 的自动补全脚本",
+			MsgCmdConfig:          "管理配置",
+			MsgCmdConfigDump:      "显示 flag 的有效值及其来源",
+			MsgCmdConfigDumpLong:  "显示指定命令每个 flag 的有效值及其来源（flag、env、config 或 default）。",
+			MsgCmdProfile:         "管理命名的 flag 预设",
+			MsgCmdProfileList:     "列出 profile",
+			MsgCmdProfileCreate:   "创建 profile",
+			MsgCmdProfileShow:     "显示 profile（默认为当前生效的 profile）",
+			MsgCmdProfileDelete:   "删除 profile",
+			MsgFlagProfileSet:     "flag 默认值，格式为 flag=value（可重复）",
+			MsgFlagProfileCommand: "取值适用的命令路径（默认为所有命令）",
+			MsgFlagProfileForce:   "覆盖已存在的 profile",
+			MsgCmdVersion:         "显示版本和构建信息",
+			MsgFlagVersionOutput:  "输出格式（text|json）",
+		},
+	},
+}
+
+// patternMessages 含有命令名称参数的界面文字
+var patternMessages = []string{MsgFlagHelp, MsgFlagVersion, MsgCmdCompletionShell}
+
+// messageKeys 默认文本 -> 消息键，用于识别 cobrax 和 cobra 内置的文字
+var messageKeys = func() map[string]string {
+	keys := make(map[string]string, len(defaultMessages))
+	for key, text := range defaultMessages {
+		keys[text] = key
+	}
+	return keys
+}()
+
+// Catalog 一种语言的翻译
+//
+// Messages 翻译 cobrax 的界面文字（键为 Msg* 常量），
+// Commands 以命令相对根命令的路径为键（根命令为 ""，例如 "server start"）翻译命令的描述、flag 说明和分组标题：
+//
+//	{
+//	    "messages": {"tree.title": "命令树（共 %d 个命令）"},
+//	    "commands": {
+//	        "": {"short": "我的应用"},
+//	        "server start": {"short": "启动服务", "flags": {"port": "监听端口"}}
+//	    }
+//	}
+type Catalog struct {
+	Messages map[string]string              `json:"messages,omitempty"`
+	Commands map[string]*CommandTranslation `json:"commands,omitempty"`
+}
+
+// CommandTranslation 单个命令的翻译，为空的字段保留原文
+type CommandTranslation struct {
+	Short   string            `json:"short,omitempty"`
+	Long    string            `json:"long,omitempty"`
+	Example string            `json:"example,omitempty"`
+	Flags   map[string]string `json:"flags,omitempty"`  // flag 名称 -> 说明
+	Groups  map[string]string `json:"groups,omitempty"` // 分组 ID -> 标题
+}
+
+// LoadCatalog 解析 JSON 格式的翻译
+func LoadCatalog(data []byte) (*Catalog, error) {
+	var catalog Catalog
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&catalog); err != nil {
+		return nil, fmt.Errorf("invalid catalog: %w", err)
+	}
+	return &catalog, nil
+}
+
+// message 返回界面文字的翻译，未翻译时返回默认文本；catalog 可以为 nil
+func (c *Catalog) message(key string) string {
+	if c != nil {
+		if text, ok := c.Messages[key]; ok {
+			return text
+		}
+	}
+	return defaultMessages[key]
+}
+
+// merge 将 other 合并到 c 中，other 中非空的翻译优先
+func (c *Catalog) merge(other *Catalog) {
+	for key, text := range other.Messages {
+		c.Messages[key] = text
+	}
+	for path, t := range other.Commands {
+		merged := c.Commands[path]
+		if merged == nil {
+			merged = &CommandTranslation{Flags: map[string]string{}, Groups: map[string]string{}}
+			c.Commands[path] = merged
+		}
+		if t.Short != "" {
+			merged.Short = t.Short
+		}
+		if t.Long != "" {
+			merged.Long = t.Long
+		}
+		if t.Example != "" {
+			merged.Example = t.Example
+		}
+		for name, usage := range t.Flags {
+			merged.Flags[name] = usage
+		}
+		for id, title := range t.Groups {
+			merged.Groups[id] = title
+		}
+	}
+}
+
+// i18nSettings 多语言设置
+type i18nSettings struct {
+	catalogs map[string]*Catalog // 用户提供的翻译，键为规范化的语言标签
+
+	locale  string   // 当前语言，默认语言时为空
+	catalog *Catalog // 当前语言合并后的翻译，默认语言时为 nil
+
+	// 原文，切换语言时据此重新翻译
+	texts     map[*spf13cobra.Command][3]string // Short、Long、Example
+	usages    map[*pflag.Flag]string
+	titles    map[*spf13cobra.Group]string
+	templates map[*spf13cobra.Command]string
+}
+
+// WithLanguages 启用多语言的帮助信息和命令树
+//
+// catalogs 的键为语言标签（如 "zh"、"zh-CN"、"ja"），可以为 nil 只使用内置的翻译（目前内置中文）。
+// 根命令会增加 --lang flag；未指定时依次读取 --lang 绑定的环境变量（见 WithEnvPrefix）和 LC_ALL、LC_MESSAGES、LANG，
+// 例如 LANG=zh_CN.UTF-8 依次合并 "zh" 和 "zh-CN" 的内置及用户翻译（越具体越优先），
+// 没有翻译的文字保留原文：
+//
+//	zh, _ := cobra.LoadCatalog(zhJSON)
+//	rootCmd := cobra.NewCommand("myapp",
+//	    cobra.WithLanguages(map[string]*cobra.Catalog{"zh": zh}),
+//	)
+func WithLanguages(catalogs map[string]*Catalog) CommandOption {
+	return func(c *Command) {
+		settings := &i18nSettings{catalogs: make(map[string]*Catalog, len(catalogs))}
+		for tag, catalog := range catalogs {
+			settings.catalogs[normalizeLocale(tag)] = catalog
+		}
+		c.i18n = settings
+		if c.PersistentFlags().Lookup(langFlagName) == nil {
+			c.PersistentFlags().String(langFlagName, "", defaultMessages[MsgFlagLang])
+			markFrameworkFlags(c.PersistentFlags(), langFlagName)
+		}
+	}
+}
+
+// Locale 返回当前使用的语言标签（如 "zh-CN"），未启用多语言或使用默认语言时返回空字符串
+func (c *Command) Locale() string {
+	if root := c.rootCommand(); root.i18n != nil {
+		return root.i18n.locale
+	}
+	return ""
+}
+
+// catalog 返回命令所在命令树当前语言的翻译，未启用或使用默认语言时返回 nil
+func (c *Command) catalog() *Catalog {
+	if root := c.rootCommand(); root.i18n != nil {
+		return root.i18n.catalog
+	}
+	return nil
+}
+
+// resolveLocale 按 --lang > 绑定的环境变量 > LC_ALL > LC_MESSAGES > LANG 确定本次执行使用的语言
+func (c *Command) resolveLocale(args []string) {
+	if c.i18n == nil {
+		return
+	}
+	locale, ok := langArg(args)
+	if !ok {
+		names := []string{"LC_ALL", "LC_MESSAGES", "LANG"}
+		if c.envPrefix != "" {
+			names = append(envVarNames(c.envPrefix, c.Command, langFlagName), names...)
+		}
+		for _, name := range names {
+			if locale = os.Getenv(name); locale != "" {
+				break
+			}
+		}
+	}
+	c.setLocale(locale)
+}
+
+// langArg 在解析前从参数中查找 --lang 的值
+func langArg(args []string) (string, bool) {
+	for i, arg := range args {
+		switch {
+		case arg == "--":
+			return "", false
+		case arg == "--"+langFlagName && i+1 < len(args):
+			return args[i+1], true
+		case strings.HasPrefix(arg, "--"+langFlagName+"="):
+			return strings.TrimPrefix(arg, "--"+langFlagName+"="), true
+		}
+	}
+	return "", false
+}
+
+// setLocale 切换语言并合并对应的翻译，locale 为空表示默认语言
+func (c *Command) setLocale(locale string) {
+	s := c.i18n
+	s.locale = normalizeLocale(locale)
+	s.catalog = nil
+	if s.locale == "" {
+		return
+	}
+
+	// 从通用到具体依次合并：zh、zh-CN，同一标签的用户翻译优先于内置翻译
+	merged := &Catalog{Messages: map[string]string{}, Commands: map[string]*CommandTranslation{}}
+	found := false
+	parts := strings.Split(s.locale, "-")
+	for i := range parts {
+		tag := strings.Join(parts[:i+1], "-")
+		for _, catalog := range []*Catalog{builtinCatalogs[tag], s.catalogs[tag]} {
+			if catalog != nil {
+				merged.merge(catalog)
+				found = true
+			}
+		}
+	}
+	if found {
+		s.catalog = merged
+	}
+}
+
+// normalizeLocale 规范化语言标签：zh_CN.UTF-8 -> zh-CN，C 和 POSIX 视为默认语言
+func normalizeLocale(locale string) string {
+	if i := strings.IndexAny(locale, ".@"); i >= 0 {
+		locale = locale[:i]
+	}
+	if locale == "C" || locale == "POSIX" {
+		return ""
+	}
+
+	parts := strings.FieldsFunc(locale, func(r rune) bool { return r == '_' || r == '-' })
+	for i, part := range parts {
+		switch {
+		case i == 0:
+			parts[i] = strings.ToLower(part)
+		case len(part) == 2:
+			parts[i] = strings.ToUpper(part)
+		default:
+			parts[i] = strings.ToUpper(part[:1]) + strings.ToLower(part[1:])
+		}
+	}
+	return strings.Join(parts, "-")
+}
+
+// localize 按当前语言翻译整棵命令树，可以重复调用（总是从原文翻译）
+func (c *Command) localize() {
+	s := c.i18n
+	if s == nil {
+		return
+	}
+	if s.texts == nil {
+		s.texts = make(map[*spf13cobra.Command][3]string)
+		s.usages = make(map[*pflag.Flag]string)
+		s.titles = make(map[*spf13cobra.Group]string)
+		s.templates = make(map[*spf13cobra.Command]string)
+	}
+
+	// 模板只在根命令上设置，子命令沿用
+	if _, ok := s.templates[c.Command]; !ok {
+		s.templates[c.Command] = c.UsageTemplate()
+	}
+	c.SetUsageTemplate(localizeTemplate(s.templates[c.Command], s.catalog))
+
+	s.localizeCommand(c.Command, "")
+}
+
+// localizeCommand 翻译命令的描述、flag 说明和分组标题，并递归处理子命令
+func (s *i18nSettings) localizeCommand(cmd *spf13cobra.Command, path string) {
+	texts, ok := s.texts[cmd]
+	if !ok {
+		texts = [3]string{cmd.Short, cmd.Long, cmd.Example}
+		s.texts[cmd] = texts
+	}
+
+	t := s.catalog.command(path)
+	cmd.Short = s.translate(t.Short, texts[0], cmd)
+	cmd.Long = s.translate(t.Long, texts[1], cmd)
+	cmd.Example = s.translate(t.Example, texts[2], cmd)
+
+	cmd.LocalFlags().VisitAll(func(flag *pflag.Flag) {
+		usage, ok := s.usages[flag]
+		if !ok {
+			usage = flag.Usage
+			s.usages[flag] = usage
+		}
+		flag.Usage = s.translate(t.Flags[flag.Name], usage, cmd)
+	})
+
+	for _, group := range cmd.Groups() {
+		title, ok := s.titles[group]
+		if !ok {
+			title = group.Title
+			s.titles[group] = title
+		}
+		group.Title = s.translate(t.Groups[group.ID], title, cmd)
+	}
+
+	for _, child := range cmd.Commands() {
+		s.localizeCommand(child, childPath(path, child.Name()))
+	}
+}
+
+// command 返回命令的翻译，没有时返回空的翻译
+func (c *Catalog) command(path string) *CommandTranslation {
+	if c != nil {
+		if t := c.Commands[path]; t != nil {
+			return t
+		}
+	}
+	return &CommandTranslation{}
+}
+
+// translate 选择文字的翻译：命令的翻译 > 内置文字的翻译 > 原文
+func (s *i18nSettings) translate(translation, original string, cmd *spf13cobra.Command) string {
+	if s.catalog == nil || original == "" {
+		return original
+	}
+	if translation != "" {
+		return translation
+	}
+	if key, ok := messageKeys[original]; ok {
+		return s.catalog.message(key)
+	}
+	for _, key := range patternMessages {
+		for _, name := range []string{cmd.DisplayName(), cmd.Name()} {
+			if fmt.Sprintf(defaultMessages[key], name) == original {
+				return fmt.Sprintf(s.catalog.message(key), name)
+			}
+		}
+	}
+	return original
+}
+
+// localizeTemplate 翻译 cobra 用法模板中的标题，catalog 为 nil 时返回原模板
+func localizeTemplate(tmpl string, catalog *Catalog) string {
+	if catalog == nil {
+		return tmpl
+	}
+	var pairs []string
+	for _, key := range []string{
+		MsgHelpUsage, MsgHelpAliases, MsgHelpExamples, MsgHelpAvailableCommands,
+		MsgHelpAdditionalCommands, MsgHelpGlobalFlags, MsgHelpFlags, MsgHelpTopics,
+	} {
+		pairs = append(pairs, defaultMessages[key], catalog.message(key))
+	}
+	const commandPath = "{{.CommandPath}}"
+	pairs = append(pairs,
+		fmt.Sprintf(defaultMessages[MsgHelpMore], commandPath),
+		fmt.Sprintf(catalog.message(MsgHelpMore), commandPath))
+	return strings.NewReplacer(pairs...).Replace(tmpl)
+}
@@ -0,0 +1,52 @@
+package cobra
+
+import "testing"
+
+func TestNormalizeLocale(t *testing.T) {
+	tests := map[string]string{
+		"":                "",
+		"C":               "",
+		"POSIX":           "",
+		"C.UTF-8":         "",
+		"zh_CN.UTF-8":     "zh-CN",
+		"zh-cn":           "zh-CN",
+		"zh_Hans_CN":      "zh-Hans-CN",
+		"en_US@euro":      "en-US",
+		"ja":              "ja",
+		"de_DE.ISO8859-1": "de-DE",
+	}
+	for locale, want := range tests {
+		if got := normalizeLocale(locale); got != want {
+			t.Errorf("normalizeLocale(%q) = %q, want %q", locale, got, want)
+		}
+	}
+}
+
+func TestSetLocaleFallback(t *testing.T) {
+	root := NewCommand("app", WithLanguages(map[string]*Catalog{
+		"zh":    {Commands: map[string]*CommandTranslation{"": {Short: "应用", Long: "应用说明"}}},
+		"zh-TW": {Commands: map[string]*CommandTranslation{"": {Short: "應用"}}},
+	}))
+
+	tests := []struct {
+		locale      string
+		short, long string
+		title       string
+	}{
+		{"zh_TW.UTF-8", "應用", "应用说明", "命令树（共 %d 个命令）"},
+		{"zh_CN.UTF-8", "应用", "应用说明", "命令树（共 %d 个命令）"},
+		{"fr_FR", "app", "app long", "Command Tree (%d commands)"},
+		{"", "app", "app long", "Command Tree (%d commands)"},
+	}
+	root.Short, root.Long = "app", "app long"
+	for _, tt := range tests {
+		root.setLocale(tt.locale)
+		root.localize()
+		if root.Short != tt.short || root.Long != tt.long {
+			t.Errorf("locale %q: got %q/%q, want %q/%q", tt.locale, root.Short, root.Long, tt.short, tt.long)
+		}
+		if got := root.catalog().message(MsgTreeTitle); got != tt.title {
+			t.Errorf("locale %q: title %q, want %q", tt.locale, got, tt.title)
+		}
+	}
+}
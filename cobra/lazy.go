@@ -0,0 +1,151 @@
+package cobra
+
+import (
+	"fmt"
+	"os"
+
+	spf13cobra "github.com/spf13/cobra"
+)
+
+// lazyCommand 懒加载占位命令的构建信息，记录在调用 AddLazyCommand 的父命令上
+type lazyCommand struct {
+	factory func() *Command // 构建完整命令的工厂函数
+}
+
+// AddLazyCommand 注册懒加载的子命令
+//
+// 注册时只创建带名称和简短描述的占位命令，完整的命令（flags、子命令）在命令解析经过它，
+// 或 --tree 等需要完整命令树的操作时才通过 factory 构建。factory 返回的命令名称必须与 name 一致。
+//
+// 使用示例：
+//
+//	rootCmd.AddLazyCommand("server", "Start the server", func() *cobra.Command {
+//	    return newServerCmd()
+//	})
+func (c *Command) AddLazyCommand(name, short string, factory func() *Command) {
+	placeholder := &spf13cobra.Command{
+		Use:                name,
+		Short:              short,
+		DisableFlagParsing: true,
+		RunE: func(cmd *spf13cobra.Command, args []string) error {
+			// 正常执行时占位命令已在解析前被替换，走到这里说明参数绕过了 cobrax 的 SetArgs
+			return NewInternalError(fmt.Errorf("lazy command %q was not materialized before execution", GetCommandFullPath(cmd))).
+				WithHint("set arguments with (*cobra.Command).SetArgs instead of the embedded spf13/cobra command")
+		},
+	}
+	if c.lazy == nil {
+		c.lazy = make(map[*spf13cobra.Command]*lazyCommand)
+	}
+	c.lazy[placeholder] = &lazyCommand{factory: factory}
+	c.recordRegistration(placeholder)
+	c.Command.AddCommand(placeholder)
+}
+
+// lazyCommandFor 返回占位命令所属的父命令和构建信息，不是占位命令时返回 nil
+func (c *Command) lazyCommandFor(cmd *spf13cobra.Command) (*Command, *lazyCommand) {
+	if cmd.Parent() == nil {
+		return nil, nil
+	}
+	owner := c.commandFor(cmd.Parent())
+	if lazy, ok := owner.lazy[cmd]; ok {
+		return owner, lazy
+	}
+	return nil, nil
+}
+
+// isLazy 判断命令是否为尚未构建的懒加载占位命令
+func (c *Command) isLazy(cmd *spf13cobra.Command) bool {
+	_, lazy := c.lazyCommandFor(cmd)
+	return lazy != nil
+}
+
+// materialize 构建懒加载命令并替换占位命令，返回完整的命令
+func (c *Command) materialize(placeholder *spf13cobra.Command) *spf13cobra.Command {
+	owner, lazy := c.lazyCommandFor(placeholder)
+	if lazy == nil {
+		return placeholder
+	}
+
+	cmd := lazy.factory()
+	if cmd == nil || cmd.Name() != placeholder.Name() {
+		panic(fmt.Sprintf("cobrax: lazy command %q: factory must return a command named %q", GetCommandFullPath(placeholder), placeholder.Name()))
+	}
+	if cmd.Short == "" {
+		cmd.Short = placeholder.Short
+	}
+	delete(owner.lazy, placeholder)
+
+	owner.replaceRegistration(placeholder, cmd.Command)
+	owner.addSubcommand(cmd)
+	owner.Command.RemoveCommand(placeholder)
+	owner.Command.AddCommand(cmd.Command)
+	return cmd.Command
+}
+
+// materializePath 沿参数路径构建懒加载命令
+//
+// 使用 cobra 自身的 Find 解析命令路径（与执行时的查找规则一致，flag 的取值不会被当作子命令），
+// 路径终点为占位命令时构建它并重新查找，直到终点为完整的命令。
+func (c *Command) materializePath(args []string) {
+	if len(args) > 0 {
+		switch args[0] {
+		case spf13cobra.ShellCompRequestCmd, spf13cobra.ShellCompNoDescRequestCmd, "help":
+			args = args[1:]
+		}
+	}
+
+	for {
+		// 参数不合法时 Find 仍返回已找到的命令，错误留给执行阶段报告
+		cmd, _, _ := c.Find(args)
+		if cmd == nil || !c.isLazy(cmd) {
+			return
+		}
+		c.materialize(cmd)
+	}
+}
+
+// findSubcommand 按名称或别名查找直接子命令
+func findSubcommand(cmd *spf13cobra.Command, name string) *spf13cobra.Command {
+	for _, child := range cmd.Commands() {
+		if child.Name() == name || child.HasAlias(name) {
+			return child
+		}
+	}
+	return nil
+}
+
+// materializeChildren 构建命令的直接子命令中的懒加载命令，返回构建后的子命令列表
+func (c *Command) materializeChildren(cmd *spf13cobra.Command) []*spf13cobra.Command {
+	// 构建过程中会修改子命令列表，先复制一份
+	children := append([]*spf13cobra.Command{}, cmd.Commands()...)
+	for _, child := range children {
+		c.materialize(child)
+	}
+	return cmd.Commands()
+}
+
+// materializeAll 构建命令下所有的懒加载命令
+func (c *Command) materializeAll(cmd *spf13cobra.Command) {
+	for _, child := range c.materializeChildren(cmd) {
+		c.materializeAll(child)
+	}
+}
+
+// SetArgs 设置执行参数（默认使用 os.Args[1:]）
+//
+// 覆盖 spf13/cobra 的同名方法，以便在解析前按参数路径构建懒加载命令。
+func (c *Command) SetArgs(args []string) {
+	c.args = args
+	c.Command.SetArgs(args)
+}
+
+// executeArgs 返回本次执行的参数
+func (c *Command) executeArgs() []string {
+	if c.args != nil {
+		return c.args
+	}
+	if len(os.Args) > 1 {
+		return os.Args[1:]
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package cobra
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+const (
+	benchGroups   = 20
+	benchCommands = 20
+	benchFlags    = 5
+)
+
+// newBenchLeaf 创建带若干 flags 的叶子命令
+func newBenchLeaf(name string) *Command {
+	cmd := NewCommand(name,
+		WithShort("Benchmark command "+name),
+		WithRun(func(cmd *Command, args []string) {}),
+	)
+	for i := 0; i < benchFlags; i++ {
+		cmd.Flags().String(fmt.Sprintf("flag-%d", i), "", "Benchmark flag")
+	}
+	return cmd
+}
+
+// newBenchGroup 创建包含 benchCommands 个叶子命令的分组
+func newBenchGroup(name string) *Command {
+	group := NewCommand(name, WithShort("Benchmark group "+name))
+	for i := 0; i < benchCommands; i++ {
+		group.AddCommand(newBenchLeaf(fmt.Sprintf("cmd%d", i)))
+	}
+	return group
+}
+
+// newBenchRoot 创建包含 benchGroups*benchCommands 个命令的根命令
+func newBenchRoot(lazy bool) *Command {
+	root := NewCommand("bench")
+	root.SetOut(io.Discard)
+	root.SetErr(io.Discard)
+	for i := 0; i < benchGroups; i++ {
+		name := fmt.Sprintf("group%d", i)
+		if lazy {
+			root.AddLazyCommand(name, "Benchmark group "+name, func() *Command {
+				return newBenchGroup(name)
+			})
+		} else {
+			root.AddCommand(newBenchGroup(name))
+		}
+	}
+	return root
+}
+
+// benchmarkStartup 构建命令树并执行 args
+func benchmarkStartup(b *testing.B, lazy bool, args ...string) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		root := newBenchRoot(lazy)
+		root.SetArgs(args)
+		if err := root.Execute(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkStartupEager(b *testing.B) {
+	benchmarkStartup(b, false, "group3", "cmd7", "--flag-1", "x")
+}
+
+func BenchmarkStartupLazy(b *testing.B) {
+	benchmarkStartup(b, true, "group3", "cmd7", "--flag-1", "x")
+}
+
+func BenchmarkStartupLazyRootOnly(b *testing.B) {
+	benchmarkStartup(b, true, "help")
+}
+
+func BenchmarkStartupLazyFullTree(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		root := newBenchRoot(true)
+		_ = DisplayFlatTree(root, nil)
+	}
+}
+
+func TestLazyCommandMaterializesAlongPath(t *testing.T) {
+	root := newBenchRoot(true)
+	root.SetArgs([]string{"group3", "cmd7", "--flag-1", "x"})
+	if err := root.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	lazy := 0
+	for _, child := range root.Commands() {
+		if root.isLazy(child) {
+			lazy++
+		}
+	}
+	if lazy != benchGroups-1 {
+		t.Fatalf("expected %d lazy groups after execution, got %d", benchGroups-1, lazy)
+	}
+
+	root.materializeAll(root.Command)
+	for _, child := range root.Commands() {
+		if child.Name() == "completion" || child.Name() == "help" {
+			continue
+		}
+		if root.isLazy(child) || len(child.Commands()) != benchCommands {
+			t.Fatalf("group %s was not fully materialized", child.Name())
+		}
+	}
+}
+
+func TestLazyCommandSkipsFlagValues(t *testing.T) {
+	t.Setenv("COBRA_TREE", "")
+	ran := ""
+	newLeaf := func(name string) func() *Command {
+		return func() *Command {
+			return NewCommand(name, WithRun(func(cmd *Command, args []string) { ran = name }))
+		}
+	}
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--profile", "config", "server"}, "server"},
+		{[]string{"-p", "config", "server"}, "server"},
+		{[]string{"--verbose", "-p", "config", "server"}, "server"},
+		{[]string{"--profile=config", "server"}, "server"},
+		// 与 cobra 的查找规则一致：合并书写的短 flag 不跳过取值
+		{[]string{"-vp", "config", "server"}, "config"},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			ran = ""
+			root := NewCommand("app")
+			root.PersistentFlags().StringP("profile", "p", "", "Profile")
+			root.PersistentFlags().BoolP("verbose", "v", false, "Verbose")
+			root.AddLazyCommand("config", "Manage configuration", newLeaf("config"))
+			root.AddLazyCommand("server", "Start the server", newLeaf("server"))
+			root.SetOut(io.Discard)
+			root.SetErr(io.Discard)
+			root.SetArgs(tt.args)
+
+			if err := root.Execute(); err != nil {
+				t.Fatal(err)
+			}
+			if ran != tt.want {
+				t.Errorf("ran %q, want %q", ran, tt.want)
+			}
+		})
+	}
+}
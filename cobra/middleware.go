@@ -0,0 +1,114 @@
+package cobra
+
+import (
+	spf13cobra "github.com/spf13/cobra"
+)
+
+// RunFunc 命令执行函数
+type RunFunc func(cmd *Command, args []string) error
+
+// Middleware 命令执行中间件，包装下一个 RunFunc
+//
+// 使用示例：
+//
+//	func Timing(next cobra.RunFunc) cobra.RunFunc {
+//	    return func(cmd *cobra.Command, args []string) error {
+//	        start := time.Now()
+//	        defer func() { cmd.PrintErrf("%s took %s\n", cmd.CommandPath(), time.Since(start)) }()
+//	        return next(cmd, args)
+//	    }
+//	}
+type Middleware func(next RunFunc) RunFunc
+
+// addSubcommand 记录通过 cobrax 添加的子命令，建立父命令的反向引用
+func (c *Command) addSubcommand(child *Command) {
+	if c.subcommands == nil {
+		c.subcommands = make(map[*spf13cobra.Command]*Command)
+	}
+	c.subcommands[child.Command] = child
+	child.parent = c
+}
+
+// rootCommand 沿父命令引用返回所在命令树的根命令
+func (c *Command) rootCommand() *Command {
+	for c.parent != nil {
+		c = c.parent
+	}
+	return c
+}
+
+// commandFor 返回 cmd 对应的 cobrax Command：从根命令沿路径查找通过 cobrax 添加的子命令，
+// 路径上遇到原始命令（如 AddSpf13Command 添加的命令）时，沿用最近的 cobrax 祖先的树形配置包装
+func (c *Command) commandFor(cmd *spf13cobra.Command) *Command {
+	root := c.rootCommand()
+
+	var path []*spf13cobra.Command
+	for p := cmd; p != root.Command; p = p.Parent() {
+		if p == nil {
+			// 不在当前命令树中
+			return &Command{Command: cmd, treeConfig: &TreeConfig{Theme: DefaultTreeTheme()}}
+		}
+		path = append([]*spf13cobra.Command{p}, path...)
+	}
+
+	current := root
+	for _, p := range path {
+		child, ok := current.subcommands[p]
+		if !ok {
+			return current.wrapCommand(cmd)
+		}
+		current = child
+	}
+	return current
+}
+
+// Use 注册中间件，作用于当前命令及其所有子命令
+//
+// 中间件按照 根命令 -> 子命令 的顺序由外向内包装，同一命令上先注册的在外层。
+func (c *Command) Use(middleware ...Middleware) {
+	c.middlewares = append(c.middlewares, middleware...)
+}
+
+// chainMiddleware 组合命令及其所有祖先上注册的中间件
+func chainMiddleware(cmd *Command, final RunFunc) RunFunc {
+	var chain []Middleware
+	for p := cmd; p != nil; p = p.parent {
+		chain = append(append([]Middleware{}, p.middlewares...), chain...)
+	}
+
+	run := final
+	for i := len(chain) - 1; i >= 0; i-- {
+		run = chain[i](run)
+	}
+	return run
+}
+
+// installMiddleware 为命令树中的每个可执行命令包装中间件链（已包装的命令记录在根命令上）
+func (c *Command) installMiddleware(cmd *spf13cobra.Command) {
+	if cmd.Runnable() && !c.wrappedRuns[cmd] {
+		if c.wrappedRuns == nil {
+			c.wrappedRuns = make(map[*spf13cobra.Command]bool)
+		}
+		c.wrappedRuns[cmd] = true
+		typeUsageErrors(cmd)
+
+		run, runE := cmd.Run, cmd.RunE
+		final := func(c *Command, args []string) error {
+			if runE != nil {
+				return runE(c.Command, args)
+			}
+			run(c.Command, args)
+			return nil
+		}
+
+		cmd.Run = nil
+		cmd.RunE = func(cc *spf13cobra.Command, args []string) error {
+			target := c.commandFor(cc)
+			return chainMiddleware(target, final)(target, args)
+		}
+	}
+
+	for _, child := range cmd.Commands() {
+		c.installMiddleware(child)
+	}
+}
@@ -0,0 +1,40 @@
+package cobra
+
+import (
+	"io"
+	"testing"
+)
+
+func TestMiddlewareScopedToTree(t *testing.T) {
+	t.Setenv("COBRA_TREE", "")
+	var calls []string
+	newTree := func(name string) (*Command, *Command) {
+		child := NewCommand("run", WithRun(func(cmd *Command, args []string) {}))
+		root := NewCommand(name, WithSubcommands(child))
+		root.SetOut(io.Discard)
+		root.SetErr(io.Discard)
+		root.SetArgs([]string{"run"})
+		return root, child
+	}
+
+	first, firstChild := newTree("first")
+	second, _ := newTree("second")
+	first.Use(func(next RunFunc) RunFunc {
+		return func(cmd *Command, args []string) error {
+			if cmd != firstChild {
+				t.Errorf("middleware got %p, want the registered command %p", cmd, firstChild)
+			}
+			calls = append(calls, cmd.Root().Name())
+			return next(cmd, args)
+		}
+	})
+
+	for _, root := range []*Command{second, first, second} {
+		if err := root.Execute(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(calls) != 1 || calls[0] != "first" {
+		t.Errorf("middleware calls = %v, want [first]", calls)
+	}
+}
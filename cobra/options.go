@@ -0,0 +1,225 @@
+package cobra
+
+import (
+	spf13cobra "github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// FlagSet flag 集合（pflag.FlagSet 的别名，便于不直接依赖 pflag）
+type FlagSet = pflag.FlagSet
+
+// PositionalArgs 位置参数校验函数
+type PositionalArgs = spf13cobra.PositionalArgs
+
+// ShellCompDirective shell 补全指令
+type ShellCompDirective = spf13cobra.ShellCompDirective
+
+// 常用的 shell 补全指令
+const (
+	ShellCompDirectiveDefault       = spf13cobra.ShellCompDirectiveDefault
+	ShellCompDirectiveError         = spf13cobra.ShellCompDirectiveError
+	ShellCompDirectiveNoSpace       = spf13cobra.ShellCompDirectiveNoSpace
+	ShellCompDirectiveNoFileComp    = spf13cobra.ShellCompDirectiveNoFileComp
+	ShellCompDirectiveFilterFileExt = spf13cobra.ShellCompDirectiveFilterFileExt
+	ShellCompDirectiveFilterDirs    = spf13cobra.ShellCompDirectiveFilterDirs
+	ShellCompDirectiveKeepOrder     = spf13cobra.ShellCompDirectiveKeepOrder
+)
+
+// 常用的位置参数校验函数
+var (
+	NoArgs        PositionalArgs = spf13cobra.NoArgs
+	ArbitraryArgs PositionalArgs = spf13cobra.ArbitraryArgs
+	OnlyValidArgs PositionalArgs = spf13cobra.OnlyValidArgs
+)
+
+// ExactArgs 要求恰好 n 个位置参数
+func ExactArgs(n int) PositionalArgs {
+	return spf13cobra.ExactArgs(n)
+}
+
+// MinimumNArgs 要求至少 n 个位置参数
+func MinimumNArgs(n int) PositionalArgs {
+	return spf13cobra.MinimumNArgs(n)
+}
+
+// MaximumNArgs 要求至多 n 个位置参数
+func MaximumNArgs(n int) PositionalArgs {
+	return spf13cobra.MaximumNArgs(n)
+}
+
+// RangeArgs 要求位置参数个数在 [min, max] 之间
+func RangeArgs(min, max int) PositionalArgs {
+	return spf13cobra.RangeArgs(min, max)
+}
+
+// MatchAll 组合多个校验函数，全部通过才算通过
+func MatchAll(validators ...PositionalArgs) PositionalArgs {
+	return spf13cobra.MatchAll(validators...)
+}
+
+// WithAliases 设置命令别名
+func WithAliases(aliases ...string) CommandOption {
+	return func(c *Command) {
+		c.Aliases = append(c.Aliases, aliases...)
+	}
+}
+
+// WithExample 设置使用示例
+func WithExample(example string) CommandOption {
+	return func(c *Command) {
+		c.Example = example
+	}
+}
+
+// WithArgs 设置位置参数校验函数，如 cobra.ExactArgs(1)
+func WithArgs(args PositionalArgs) CommandOption {
+	return func(c *Command) {
+		c.Args = args
+	}
+}
+
+// WithValidArgs 设置位置参数的可选值，用于补全和 OnlyValidArgs 校验
+func WithValidArgs(args ...string) CommandOption {
+	return func(c *Command) {
+		c.ValidArgs = append(c.ValidArgs, args...)
+	}
+}
+
+// WithValidArgsFunction 设置位置参数的动态补全函数
+func WithValidArgsFunction(fn func(cmd *Command, args []string, toComplete string) ([]string, ShellCompDirective)) CommandOption {
+	return func(c *Command) {
+		c.ValidArgsFunction = func(cmd *spf13cobra.Command, args []string, toComplete string) ([]string, ShellCompDirective) {
+			return fn(c.wrapCommand(cmd), args, toComplete)
+		}
+	}
+}
+
+// WithFlagCompletion 设置 flag 值的动态补全函数，flag 必须已经定义（如在 WithFlags 之后使用）
+func WithFlagCompletion(flag string, fn func(cmd *Command, args []string, toComplete string) ([]string, ShellCompDirective)) CommandOption {
+	return func(c *Command) {
+		err := c.RegisterFlagCompletionFunc(flag, func(cmd *spf13cobra.Command, args []string, toComplete string) ([]string, ShellCompDirective) {
+			return fn(c.wrapCommand(cmd), args, toComplete)
+		})
+		if err != nil {
+			panic("cobrax: WithFlagCompletion on " + c.Name() + ": " + err.Error())
+		}
+	}
+}
+
+// WithHidden 隐藏命令（不出现在帮助、树形视图和补全中）
+func WithHidden() CommandOption {
+	return func(c *Command) {
+		c.Hidden = true
+	}
+}
+
+// WithDeprecated 标记命令已废弃，执行时输出 message
+func WithDeprecated(message string) CommandOption {
+	return func(c *Command) {
+		c.Deprecated = message
+	}
+}
+
+// WithAnnotation 设置命令注解
+func WithAnnotation(key, value string) CommandOption {
+	return func(c *Command) {
+		if c.Annotations == nil {
+			c.Annotations = make(map[string]string)
+		}
+		c.Annotations[key] = value
+	}
+}
+
+// WithPreRun 设置在执行函数之前运行的钩子
+func WithPreRun(fn func(*Command, []string) error) CommandOption {
+	return func(c *Command) {
+		c.PreRunE = func(cmd *spf13cobra.Command, args []string) error {
+			return fn(c.wrapCommand(cmd), args)
+		}
+	}
+}
+
+// WithPostRun 设置在执行函数之后运行的钩子
+func WithPostRun(fn func(*Command, []string) error) CommandOption {
+	return func(c *Command) {
+		c.PostRunE = func(cmd *spf13cobra.Command, args []string) error {
+			return fn(c.wrapCommand(cmd), args)
+		}
+	}
+}
+
+// WithPersistentPreRun 设置作用于当前命令及所有子命令的前置钩子
+//
+// 各级命令的前置钩子按 根命令 -> 子命令 的顺序依次执行。
+func WithPersistentPreRun(fn func(*Command, []string) error) CommandOption {
+	return func(c *Command) {
+		c.PersistentPreRunE = func(cmd *spf13cobra.Command, args []string) error {
+			return fn(c.wrapCommand(cmd), args)
+		}
+	}
+}
+
+// WithPersistentPostRun 设置作用于当前命令及所有子命令的后置钩子
+//
+// 各级命令的后置钩子按 子命令 -> 根命令 的顺序依次执行。
+func WithPersistentPostRun(fn func(*Command, []string) error) CommandOption {
+	return func(c *Command) {
+		c.PersistentPostRunE = func(cmd *spf13cobra.Command, args []string) error {
+			return fn(c.wrapCommand(cmd), args)
+		}
+	}
+}
+
+// WithSilenceErrors 执行出错时不输出错误信息
+func WithSilenceErrors() CommandOption {
+	return func(c *Command) {
+		c.SilenceErrors = true
+	}
+}
+
+// WithSilenceUsage 执行出错时不输出用法
+func WithSilenceUsage() CommandOption {
+	return func(c *Command) {
+		c.SilenceUsage = true
+	}
+}
+
+// WithFlags 定义命令的本地 flags
+//
+//	cobra.WithFlags(func(flags *cobra.FlagSet) {
+//	    flags.BoolP("force", "f", false, "Force overwrite existing config")
+//	})
+func WithFlags(fn func(flags *FlagSet)) CommandOption {
+	return func(c *Command) {
+		fn(c.Command.Flags())
+	}
+}
+
+// WithPersistentFlags 定义会被所有子命令继承的 flags
+func WithPersistentFlags(fn func(flags *FlagSet)) CommandOption {
+	return func(c *Command) {
+		fn(c.Command.PersistentFlags())
+	}
+}
+
+// WithRequiredFlags 将已定义的 flags（本地或持久）标记为必填
+func WithRequiredFlags(names ...string) CommandOption {
+	return func(c *Command) {
+		for _, name := range names {
+			mark := c.MarkFlagRequired
+			if c.Command.Flags().Lookup(name) == nil && c.Command.PersistentFlags().Lookup(name) != nil {
+				mark = c.MarkPersistentFlagRequired
+			}
+			if err := mark(name); err != nil {
+				panic("cobrax: WithRequiredFlags on " + c.Name() + ": " + err.Error())
+			}
+		}
+	}
+}
+
+// WithSubcommands 添加子命令
+func WithSubcommands(cmds ...*Command) CommandOption {
+	return func(c *Command) {
+		c.AddCommand(cmds...)
+	}
+}
@@ -0,0 +1,497 @@
+package cobra
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	spf13cobra "github.com/spf13/cobra"
+)
+
+// OutputFormat 命令结果的输出格式
+type OutputFormat string
+
+const (
+	// OutputTable 带主题的表格（默认）
+	OutputTable OutputFormat = "table"
+	// OutputJSON 缩进的 JSON
+	OutputJSON OutputFormat = "json"
+	// OutputJSONLines 每个元素一行的 JSON
+	OutputJSONLines OutputFormat = "jsonl"
+	// OutputYAML YAML
+	OutputYAML OutputFormat = "yaml"
+	// OutputTemplate Go 模板，通过 --output template=<模板> 指定
+	OutputTemplate OutputFormat = "template"
+)
+
+// outputFormats 支持的输出格式（用于补全和错误提示）
+var outputFormats = []OutputFormat{OutputTable, OutputJSON, OutputJSONLines, OutputYAML, OutputTemplate}
+
+// 输出相关的 flag 名称
+const (
+	outputFlagName    = "output"
+	columnsFlagName   = "columns"
+	sortByFlagName    = "sort-by"
+	noHeadersFlagName = "no-headers"
+)
+
+// outputSettings 结构化输出设置
+type outputSettings struct {
+	format OutputFormat // 未指定 --output 时的格式
+}
+
+// OutputOptions 结构化输出选项
+type OutputOptions struct {
+	Format    OutputFormat
+	Template  string     // Format 为 OutputTemplate 时使用的模板
+	Columns   []string   // 表格中显示的列（不区分大小写），为空时显示所有列
+	SortBy    string     // 列表按该列排序，以 "-" 开头时降序
+	NoHeaders bool       // 表格不输出表头
+	Theme     *TreeTheme // 表格使用的主题，为空时使用默认主题
+}
+
+// WithOutput 为根命令增加结构化输出 flags，执行函数通过 PrintResult 输出结果
+//
+//	--output, -o   table|json|jsonl|yaml|template=<模板>（默认为 format）
+//	--columns      表格中显示的列，例如 --columns name,port
+//	--sort-by      列表按该列排序，以 "-" 开头时降序，例如 --sort-by=-port
+//	--no-headers   表格不输出表头
+//
+// format 为空时使用 OutputTable。
+func WithOutput(format OutputFormat) CommandOption {
+	return func(c *Command) {
+		if format == "" {
+			format = OutputTable
+		}
+		c.output = &outputSettings{format: format}
+
+		flags := c.PersistentFlags()
+		if flags.Lookup(outputFlagName) != nil {
+			return
+		}
+		flags.StringP(outputFlagName, "o", string(format), defaultMessages[MsgFlagOutput])
+		flags.StringSlice(columnsFlagName, nil, defaultMessages[MsgFlagColumns])
+		flags.String(sortByFlagName, "", defaultMessages[MsgFlagSortBy])
+		flags.Bool(noHeadersFlagName, false, defaultMessages[MsgFlagNoHeaders])
+		markFrameworkFlags(flags, outputFlagName, columnsFlagName, sortByFlagName, noHeadersFlagName)
+
+		_ = c.RegisterFlagCompletionFunc(outputFlagName, func(cmd *spf13cobra.Command, args []string, toComplete string) ([]string, ShellCompDirective) {
+			completions := make([]string, 0, len(outputFormats))
+			for _, f := range outputFormats {
+				completions = append(completions, string(f))
+			}
+			return completions, ShellCompDirectiveNoFileComp
+		})
+	}
+}
+
+// PrintResult 按 --output 等 flags 将 v 写入命令的标准输出
+//
+// v 可以是结构体、map 或它们的切片：表格中每个元素为一行，结构体的导出字段为列
+// （列名依次取自 output、json 标签和字段名，output:"-" 表示不显示），其它值输出为单列。
+// 未启用 WithOutput 时使用表格输出。
+func (c *Command) PrintResult(v interface{}) error {
+	opts, err := c.outputOptions()
+	if err != nil {
+		return err
+	}
+	return WriteOutput(c.OutOrStdout(), v, opts)
+}
+
+// outputOptions 从 flags 读取输出选项
+func (c *Command) outputOptions() (OutputOptions, error) {
+	opts := OutputOptions{Format: OutputTable, Theme: c.Theme()}
+	if root := c.rootCommand(); root.output != nil {
+		opts.Format = root.output.format
+	}
+
+	flags := c.Flags()
+	if output, err := flags.GetString(outputFlagName); err == nil && output != "" {
+		format, tmpl, ok := strings.Cut(output, "=")
+		opts.Format, opts.Template = OutputFormat(format), tmpl
+		if ok && opts.Format != OutputTemplate {
+			return opts, invalidOutputFormat(output)
+		}
+	}
+	opts.Columns, _ = flags.GetStringSlice(columnsFlagName)
+	opts.SortBy, _ = flags.GetString(sortByFlagName)
+	opts.NoHeaders, _ = flags.GetBool(noHeadersFlagName)
+	return opts, nil
+}
+
+// invalidOutputFormat 不支持的输出格式错误
+func invalidOutputFormat(format string) error {
+	names := make([]string, 0, len(outputFormats))
+	for _, f := range outputFormats {
+		names = append(names, string(f))
+	}
+	names[len(names)-1] += "=<template>"
+	return NewUsageError("unsupported output format %q", format).
+		WithHint("use one of: " + strings.Join(names, ", "))
+}
+
+// WriteOutput 按 opts 将 v 写入 w
+func WriteOutput(w io.Writer, v interface{}, opts OutputOptions) error {
+	value := indirect(reflect.ValueOf(v))
+	if opts.SortBy != "" {
+		sorted, err := sortRecords(value, opts.SortBy)
+		if err != nil {
+			return err
+		}
+		value, v = sorted, sorted.Interface()
+	}
+
+	switch opts.Format {
+	case OutputTable, "":
+		return writeTable(w, value, opts)
+	case OutputJSON:
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	case OutputJSONLines:
+		return writeJSONLines(w, value)
+	case OutputYAML:
+		return writeYAML(w, v)
+	case OutputTemplate:
+		return writeTemplate(w, v, opts.Template)
+	default:
+		return invalidOutputFormat(string(opts.Format))
+	}
+}
+
+// writeJSONLines 切片的每个元素输出为一行 JSON，其它值输出为一行
+func writeJSONLines(w io.Writer, value reflect.Value) error {
+	items := []reflect.Value{value}
+	if isList(value) {
+		items = items[:0]
+		for i := 0; i < value.Len(); i++ {
+			items = append(items, value.Index(i))
+		}
+	}
+	for _, item := range items {
+		var data []byte
+		var err error
+		if item.IsValid() {
+			data, err = json.Marshal(item.Interface())
+		} else {
+			data = []byte("null")
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(w, string(data)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeTemplate 使用 Go 模板输出
+func writeTemplate(w io.Writer, v interface{}, text string) error {
+	if text == "" {
+		return NewUsageError("missing template").WithHint("use --output template='{{.Name}}'")
+	}
+	tmpl, err := template.New("output").Parse(text)
+	if err != nil {
+		return NewUsageError("invalid output template: %v", err)
+	}
+	var builder strings.Builder
+	if err := tmpl.Execute(&builder, v); err != nil {
+		return err
+	}
+	output := builder.String()
+	if !strings.HasSuffix(output, "\n") {
+		output += "\n"
+	}
+	_, err = io.WriteString(w, output)
+	return err
+}
+
+// writeTable 以 Table 输出，列之间以两个空格分隔
+func writeTable(w io.Writer, value reflect.Value, opts OutputOptions) error {
+	columns, rows := tabulate(value)
+	columns, rows, err := selectColumns(columns, rows, opts.Columns)
+	if err != nil || len(columns) == 0 {
+		return err
+	}
+
+	table := &Table{Rows: rows}
+	if !opts.NoHeaders {
+		for _, column := range columns {
+			table.Headers = append(table.Headers, column.header())
+		}
+	}
+	if output := table.Render(opts.Theme); output != "" {
+		_, err = fmt.Fprintln(w, output)
+	}
+	return err
+}
+
+// outputColumn 表格的列
+type outputColumn struct {
+	name  string
+	index []int // 结构体字段的索引，map 的列为 nil
+	whole bool  // 元素本身（既不是结构体也不是 map 的元素）
+}
+
+// header 列的表头
+func (c outputColumn) header() string {
+	return strings.ToUpper(c.name)
+}
+
+// tabulate 将值转换为列和行：切片的每个元素为一行，其它值为一行
+func tabulate(value reflect.Value) ([]outputColumn, [][]string) {
+	if !value.IsValid() {
+		return nil, nil
+	}
+
+	var items []reflect.Value
+	elemType := value.Type()
+	if isList(value) {
+		elemType = value.Type().Elem()
+		for i := 0; i < value.Len(); i++ {
+			items = append(items, value.Index(i))
+		}
+	} else {
+		items = []reflect.Value{value}
+	}
+	for elemType.Kind() == reflect.Pointer {
+		elemType = elemType.Elem()
+	}
+
+	var columns []outputColumn
+	switch {
+	case elemType.Kind() == reflect.Struct:
+		columns = structColumns(elemType)
+	case elemType.Kind() == reflect.Map && elemType.Key().Kind() == reflect.String:
+		columns = mapColumns(items)
+	default:
+		columns = []outputColumn{{name: "value", whole: true}}
+	}
+
+	rows := make([][]string, 0, len(items))
+	for _, item := range items {
+		row := make([]string, len(columns))
+		for i, column := range columns {
+			row[i] = formatCell(column.value(indirect(item)))
+		}
+		rows = append(rows, row)
+	}
+	return columns, rows
+}
+
+// structColumns 结构体的列：导出字段（包括嵌入结构体提升的字段）
+func structColumns(t reflect.Type) []outputColumn {
+	var columns []outputColumn
+	for _, field := range reflect.VisibleFields(t) {
+		if !field.IsExported() || (field.Anonymous && indirectType(field.Type).Kind() == reflect.Struct) {
+			continue
+		}
+		name := fieldName(field)
+		if name == "-" {
+			continue
+		}
+		columns = append(columns, outputColumn{name: name, index: field.Index})
+	}
+	return columns
+}
+
+// fieldName 字段的列名：output 标签 > json 标签 > 字段名
+func fieldName(field reflect.StructField) string {
+	for _, key := range []string{"output", "json"} {
+		if tag, ok := field.Tag.Lookup(key); ok {
+			if name, _, _ := strings.Cut(tag, ","); name != "" {
+				return name
+			}
+		}
+	}
+	return field.Name
+}
+
+// mapColumns map 的列：所有元素的键，按名称排序
+func mapColumns(items []reflect.Value) []outputColumn {
+	seen := make(map[string]bool)
+	var names []string
+	for _, item := range items {
+		item = indirect(item)
+		if !item.IsValid() {
+			continue
+		}
+		for _, key := range item.MapKeys() {
+			if name := key.String(); !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+
+	columns := make([]outputColumn, len(names))
+	for i, name := range names {
+		columns[i] = outputColumn{name: name}
+	}
+	return columns
+}
+
+// value 返回元素在该列的值，不存在时返回无效值
+func (c outputColumn) value(item reflect.Value) reflect.Value {
+	switch {
+	case !item.IsValid() || c.whole:
+		return item
+	case item.Kind() == reflect.Struct:
+		field, err := item.FieldByIndexErr(c.index)
+		if err != nil {
+			return reflect.Value{}
+		}
+		return field
+	case item.Kind() == reflect.Map:
+		return item.MapIndex(reflect.ValueOf(c.name).Convert(item.Type().Key()))
+	default:
+		return reflect.Value{}
+	}
+}
+
+// selectColumns 按名称（不区分大小写）选择并排列列
+func selectColumns(columns []outputColumn, rows [][]string, names []string) ([]outputColumn, [][]string, error) {
+	if len(names) == 0 {
+		return columns, rows, nil
+	}
+
+	indexes := make([]int, 0, len(names))
+	for _, name := range names {
+		i := findColumn(columns, name)
+		if i < 0 {
+			return nil, nil, unknownColumn(columns, name)
+		}
+		indexes = append(indexes, i)
+	}
+
+	selected := make([]outputColumn, len(indexes))
+	for i, index := range indexes {
+		selected[i] = columns[index]
+	}
+	selectedRows := make([][]string, len(rows))
+	for r, row := range rows {
+		selectedRows[r] = make([]string, len(indexes))
+		for i, index := range indexes {
+			selectedRows[r][i] = row[index]
+		}
+	}
+	return selected, selectedRows, nil
+}
+
+// findColumn 按名称查找列，不存在时返回 -1
+func findColumn(columns []outputColumn, name string) int {
+	for i, column := range columns {
+		if strings.EqualFold(column.name, strings.TrimSpace(name)) {
+			return i
+		}
+	}
+	return -1
+}
+
+// unknownColumn 未知列错误
+func unknownColumn(columns []outputColumn, name string) error {
+	names := make([]string, len(columns))
+	for i, column := range columns {
+		names[i] = column.name
+	}
+	return NewUsageError("unknown column %q", name).
+		WithHint("available columns: " + strings.Join(names, ", "))
+}
+
+// sortRecords 按列对切片排序（返回排序后的副本），数字按数值比较，以 "-" 开头时降序
+func sortRecords(value reflect.Value, by string) (reflect.Value, error) {
+	if !isList(value) {
+		return value, nil
+	}
+	descending := strings.HasPrefix(by, "-")
+	columns, rows := tabulate(value)
+	index := findColumn(columns, strings.TrimPrefix(by, "-"))
+	if index < 0 {
+		return value, unknownColumn(columns, strings.TrimPrefix(by, "-"))
+	}
+
+	order := make([]int, len(rows))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := rows[order[i]][index], rows[order[j]][index]
+		if descending {
+			a, b = b, a
+		}
+		return lessCell(a, b)
+	})
+
+	sorted := reflect.MakeSlice(reflect.SliceOf(value.Type().Elem()), len(order), len(order))
+	for i, from := range order {
+		sorted.Index(i).Set(value.Index(from))
+	}
+	return sorted, nil
+}
+
+// lessCell 比较两个单元格：都是数字时按数值比较，否则按字符串比较
+func lessCell(a, b string) bool {
+	x, errA := strconv.ParseFloat(a, 64)
+	y, errB := strconv.ParseFloat(b, 64)
+	if errA == nil && errB == nil {
+		return x < y
+	}
+	return a < b
+}
+
+// formatCell 将值格式化为单元格文本：切片以逗号连接，nil 为空
+func formatCell(value reflect.Value) string {
+	value = indirect(value)
+	if !value.IsValid() {
+		return ""
+	}
+	if stringer, ok := value.Interface().(fmt.Stringer); ok {
+		return stringer.String()
+	}
+	if isList(value) {
+		items := make([]string, value.Len())
+		for i := range items {
+			items[i] = formatCell(value.Index(i))
+		}
+		return strings.Join(items, ",")
+	}
+	return fmt.Sprint(value.Interface())
+}
+
+// isList 判断是否为切片或数组（[]byte 除外）
+func isList(value reflect.Value) bool {
+	if !value.IsValid() {
+		return false
+	}
+	kind := value.Kind()
+	return (kind == reflect.Slice || kind == reflect.Array) && value.Type().Elem().Kind() != reflect.Uint8
+}
+
+// indirect 解开指针和接口，nil 时返回无效值
+func indirect(value reflect.Value) reflect.Value {
+	for value.IsValid() && (value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface) {
+		if value.IsNil() {
+			return reflect.Value{}
+		}
+		value = value.Elem()
+	}
+	return value
+}
+
+// indirectType 解开指针类型
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
@@ -0,0 +1,157 @@
+package cobra
+
+import (
+	"strings"
+	"testing"
+)
+
+type testService struct {
+	Name    string   `json:"name"`
+	Port    int      `json:"port"`
+	Tags    []string `json:"tags,omitempty"`
+	Enabled bool     `json:"enabled"`
+	Secret  string   `json:"-"`
+	Note    string   `json:"note,omitempty" output:"-"`
+}
+
+var testServices = []testService{
+	{Name: "web", Port: 8080, Tags: []string{"http", "public"}, Enabled: true},
+	{Name: "db", Port: 5432, Note: "primary"},
+	{Name: "cache", Port: 6379, Enabled: true},
+}
+
+func TestWriteOutput(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+		opts OutputOptions
+		want string
+	}{
+		{
+			name: "table",
+			v:    testServices,
+			opts: OutputOptions{Format: OutputTable},
+			want: `
+NAME   PORT  TAGS         ENABLED
+web    8080  http,public  true
+db     5432               false
+cache  6379               true
+`,
+		},
+		{
+			name: "columns sorted descending without headers",
+			v:    testServices,
+			opts: OutputOptions{Format: OutputTable, Columns: []string{"port", "NAME"}, SortBy: "-port", NoHeaders: true},
+			want: `
+8080  web
+6379  cache
+5432  db
+`,
+		},
+		{
+			name: "single struct",
+			v:    &testServices[1],
+			opts: OutputOptions{Format: OutputTable, Columns: []string{"name"}},
+			want: `
+NAME
+db
+`,
+		},
+		{
+			name: "maps",
+			v:    []map[string]interface{}{{"b": 1, "a": "x"}, {"c": true}},
+			opts: OutputOptions{Format: OutputTable},
+			want: `
+A  B  C
+x  1
+      true
+`,
+		},
+		{
+			name: "scalars",
+			v:    []string{"one", "two"},
+			opts: OutputOptions{Format: OutputTable, SortBy: "value"},
+			want: `
+VALUE
+one
+two
+`,
+		},
+		{
+			name: "jsonl sorted",
+			v:    testServices,
+			opts: OutputOptions{Format: OutputJSONLines, SortBy: "name"},
+			want: `
+{"name":"cache","port":6379,"enabled":true}
+{"name":"db","port":5432,"enabled":false,"note":"primary"}
+{"name":"web","port":8080,"tags":["http","public"],"enabled":true}
+`,
+		},
+		{
+			name: "yaml",
+			v:    testServices[:2],
+			opts: OutputOptions{Format: OutputYAML},
+			want: `
+- name: web
+  port: 8080
+  tags:
+    - http
+    - public
+  enabled: true
+- name: db
+  port: 5432
+  enabled: false
+  note: primary
+`,
+		},
+		{
+			name: "yaml quoting",
+			v:    map[string]interface{}{"empty": "", "number": "8080", "colon": "a: b", "nested": map[string]interface{}{}, "list": []int{}},
+			opts: OutputOptions{Format: OutputYAML},
+			want: `
+colon: "a: b"
+empty: ""
+list: []
+nested: {}
+number: "8080"
+`,
+		},
+		{
+			name: "template",
+			v:    testServices,
+			opts: OutputOptions{Format: OutputTemplate, Template: "{{range .}}{{.Name}}={{.Port}} {{end}}", SortBy: "port"},
+			want: `
+db=5432 cache=6379 web=8080 
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var builder strings.Builder
+			if err := WriteOutput(&builder, tt.v, tt.opts); err != nil {
+				t.Fatal(err)
+			}
+			if got, want := builder.String(), strings.TrimPrefix(tt.want, "\n"); got != want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestWriteOutputErrors(t *testing.T) {
+	tests := map[string]OutputOptions{
+		"unknown format":   {Format: "xml"},
+		"unknown column":   {Format: OutputTable, Columns: []string{"missing"}},
+		"unknown sort":     {Format: OutputJSON, SortBy: "missing"},
+		"missing template": {Format: OutputTemplate},
+	}
+	for name, opts := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := WriteOutput(&strings.Builder{}, testServices, opts)
+			if err == nil || AsError(err).Category != CategoryUsage {
+				t.Errorf("got %v, want a usage error", err)
+			}
+		})
+	}
+}
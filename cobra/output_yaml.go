@@ -0,0 +1,170 @@
+package cobra
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// yamlMap 保持键顺序的对象
+type yamlMap struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+// writeYAML 以 YAML 输出 v
+//
+// 先按 JSON 序列化（遵循 json 标签和 json.Marshaler），再按字段顺序转换为 YAML。
+func writeYAML(w io.Writer, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	value, err := decodeOrdered(decoder)
+	if err != nil {
+		return err
+	}
+
+	var builder strings.Builder
+	switch value.(type) {
+	case *yamlMap, []interface{}:
+		writeYAMLValue(&builder, value, "")
+	default:
+		builder.WriteString(yamlScalar(value))
+		builder.WriteString("\n")
+	}
+	_, err = io.WriteString(w, builder.String())
+	return err
+}
+
+// decodeOrdered 解码 JSON 值，对象保持键的顺序
+func decodeOrdered(decoder *json.Decoder) (interface{}, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch token {
+	case json.Delim('{'):
+		m := &yamlMap{values: make(map[string]interface{})}
+		for decoder.More() {
+			key, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeOrdered(decoder)
+			if err != nil {
+				return nil, err
+			}
+			m.keys = append(m.keys, key.(string))
+			m.values[key.(string)] = value
+		}
+		_, err = decoder.Token()
+		return m, err
+	case json.Delim('['):
+		list := []interface{}{}
+		for decoder.More() {
+			value, err := decodeOrdered(decoder)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, value)
+		}
+		_, err = decoder.Token()
+		return list, err
+	default:
+		return token, nil
+	}
+}
+
+// writeYAMLValue 输出对象或列表，每行以 indent 开头
+func writeYAMLValue(builder *strings.Builder, value interface{}, indent string) {
+	switch v := value.(type) {
+	case *yamlMap:
+		for _, key := range v.keys {
+			builder.WriteString(indent + yamlString(key) + ":")
+			writeYAMLChild(builder, v.values[key], indent+"  ")
+		}
+	case []interface{}:
+		for _, item := range v {
+			if isYAMLCollection(item) {
+				// 非空集合的第一行与 "- " 写在同一行
+				var nested strings.Builder
+				writeYAMLValue(&nested, item, indent+"  ")
+				builder.WriteString(indent + "- " + strings.TrimPrefix(nested.String(), indent+"  "))
+				continue
+			}
+			builder.WriteString(indent + "-")
+			writeYAMLChild(builder, item, indent+"  ")
+		}
+	}
+}
+
+// isYAMLCollection 判断是否为非空的对象或列表
+func isYAMLCollection(value interface{}) bool {
+	switch v := value.(type) {
+	case *yamlMap:
+		return len(v.keys) > 0
+	case []interface{}:
+		return len(v) > 0
+	}
+	return false
+}
+
+// writeYAMLChild 输出键或列表项之后的值：标量和空集合写在同一行，其余换行缩进
+func writeYAMLChild(builder *strings.Builder, value interface{}, indent string) {
+	switch v := value.(type) {
+	case *yamlMap:
+		if len(v.keys) == 0 {
+			builder.WriteString(" {}\n")
+			return
+		}
+	case []interface{}:
+		if len(v) == 0 {
+			builder.WriteString(" []\n")
+			return
+		}
+	default:
+		builder.WriteString(" " + yamlScalar(value) + "\n")
+		return
+	}
+	builder.WriteString("\n")
+	writeYAMLValue(builder, value, indent)
+}
+
+// yamlScalar 格式化标量
+func yamlScalar(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(v)
+	case json.Number:
+		return v.String()
+	case string:
+		return yamlString(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// yamlString 格式化字符串，可能被解析为其它类型或含有特殊字符时加双引号
+func yamlString(s string) string {
+	if s == "" || s != strings.TrimSpace(s) || strings.ContainsAny(s, "\n\t\"'#`") ||
+		strings.Contains(s, ": ") || strings.HasSuffix(s, ":") ||
+		strings.ContainsAny(s[:1], "-?:,[]{}&*!|>%@") {
+		return strconv.Quote(s)
+	}
+	switch strings.ToLower(s) {
+	case "true", "false", "yes", "no", "on", "off", "null", "~", "y", "n":
+		return strconv.Quote(s)
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return strconv.Quote(s)
+	}
+	return s
+}
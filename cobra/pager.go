@@ -0,0 +1,110 @@
+package cobra
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/charmbracelet/x/term"
+	spf13cobra "github.com/spf13/cobra"
+)
+
+// noPagerFlagName 禁用分页器的 flag 名称
+const noPagerFlagName = "no-pager"
+
+// defaultPager 未设置 COBRA_PAGER 和 PAGER 时使用的分页器
+const defaultPager = "less -R"
+
+// 终端检测函数，测试时可替换
+var (
+	isTerminal   = term.IsTerminal
+	terminalSize = term.GetSize
+)
+
+// pagerCommand 返回分页器命令及参数：COBRA_PAGER > PAGER > less -R
+func pagerCommand() []string {
+	for _, name := range []string{"COBRA_PAGER", "PAGER"} {
+		if value := strings.TrimSpace(os.Getenv(name)); value != "" {
+			return strings.Fields(value)
+		}
+	}
+	return strings.Fields(defaultPager)
+}
+
+// pagerTerminal 返回可能需要分页的终端：未设置 --no-pager 且标准输出为终端，否则返回 nil
+func pagerTerminal(cmd *spf13cobra.Command) *os.File {
+	if noPager, err := cmd.Flags().GetBool(noPagerFlagName); err == nil && noPager {
+		return nil
+	}
+	file, ok := cmd.OutOrStdout().(*os.File)
+	if !ok || !isTerminal(file.Fd()) {
+		return nil
+	}
+	return file
+}
+
+// writePaged 将文本写入 cmd 的标准输出；输出为终端且文本超过终端高度时通过分页器显示
+//
+// 找不到或无法启动分页器时直接输出。
+func writePaged(cmd *spf13cobra.Command, output string) error {
+	if file := pagerTerminal(cmd); file != nil && exceedsHeight(file, output) && runPager(file, output) {
+		return nil
+	}
+	_, err := fmt.Fprint(cmd.OutOrStdout(), output)
+	return err
+}
+
+// withPager 缓存 fn 写入 cmd 标准输出的内容，再通过 writePaged 输出；不可能分页时直接执行 fn
+func withPager(cmd *spf13cobra.Command, fn func()) {
+	file := pagerTerminal(cmd)
+	if file == nil {
+		fn()
+		return
+	}
+
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	defer func() {
+		cmd.SetOut(file)
+		_ = writePaged(cmd, buf.String())
+	}()
+	fn()
+}
+
+// exceedsHeight 判断文本是否超过终端高度（保留一行给 shell 提示符）
+func exceedsHeight(file *os.File, output string) bool {
+	_, height, err := terminalSize(file.Fd())
+	if err != nil || height <= 0 {
+		return false
+	}
+	lines := strings.Count(output, "\n")
+	if !strings.HasSuffix(output, "\n") {
+		lines++
+	}
+	return lines >= height
+}
+
+// runPager 启动分页器显示文本，分页器不存在或无法启动时返回 false
+func runPager(file *os.File, output string) bool {
+	args := pagerCommand()
+	if len(args) == 0 {
+		return false
+	}
+	path, err := exec.LookPath(args[0])
+	if err != nil {
+		return false
+	}
+
+	pager := exec.Command(path, args[1:]...)
+	pager.Stdin = strings.NewReader(output)
+	pager.Stdout = file
+	pager.Stderr = os.Stderr
+	if err := pager.Start(); err != nil {
+		return false
+	}
+	// 用户提前退出分页器时分页器可能返回错误，此时内容已经显示过，不再重复输出
+	_ = pager.Wait()
+	return true
+}
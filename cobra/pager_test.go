@@ -0,0 +1,126 @@
+package cobra
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	spf13cobra "github.com/spf13/cobra"
+)
+
+// stubTerminal 让所有文件都被视为高度为 height 的终端，height <= 0 时获取尺寸失败
+func stubTerminal(t *testing.T, height int) {
+	t.Helper()
+	oldIsTerminal, oldSize := isTerminal, terminalSize
+	t.Cleanup(func() { isTerminal, terminalSize = oldIsTerminal, oldSize })
+
+	isTerminal = func(fd uintptr) bool { return true }
+	terminalSize = func(fd uintptr) (int, int, error) {
+		if height <= 0 {
+			return 0, 0, errors.New("not a terminal")
+		}
+		return 80, height, nil
+	}
+}
+
+func TestPagerCommand(t *testing.T) {
+	tests := []struct {
+		name       string
+		cobraPager string
+		pager      string
+		want       []string
+	}{
+		{"default", "", "", []string{"less", "-R"}},
+		{"PAGER", "", "more -s", []string{"more", "-s"}},
+		{"COBRA_PAGER wins", "most", "more -s", []string{"most"}},
+		{"blank COBRA_PAGER", "  ", "more", []string{"more"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("COBRA_PAGER", tt.cobraPager)
+			t.Setenv("PAGER", tt.pager)
+			if got := pagerCommand(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("pagerCommand() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExceedsHeight(t *testing.T) {
+	tests := []struct {
+		name   string
+		height int
+		output string
+		want   bool
+	}{
+		{"shorter", 3, "a\nb\n", false},
+		{"fills the screen", 3, "a\nb\nc\n", true},
+		{"no trailing newline", 3, "a\nb\nc", true},
+		{"unknown size", 0, "a\nb\nc\nd\n", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stubTerminal(t, tt.height)
+			if got := exceedsHeight(os.Stdout, tt.output); got != tt.want {
+				t.Errorf("exceedsHeight(%q) with height %d = %v, want %v", tt.output, tt.height, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWritePaged(t *testing.T) {
+	if _, err := exec.LookPath("tr"); err != nil {
+		t.Skip("tr not available")
+	}
+	long := "one\ntwo\nthree\nfour\n"
+
+	tests := []struct {
+		name    string
+		output  string
+		pager   string
+		noPager bool
+		want    string
+	}{
+		{"fits on screen", "one\n", "tr a-z A-Z", false, "one\n"},
+		{"paged", long, "tr a-z A-Z", false, strings.ToUpper(long)},
+		{"--no-pager", long, "tr a-z A-Z", true, long},
+		{"missing pager", long, "cobrax-no-such-pager", false, long},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stubTerminal(t, 3)
+			t.Setenv("COBRA_PAGER", tt.pager)
+
+			out, err := os.Create(filepath.Join(t.TempDir(), "out"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer out.Close()
+
+			cmd := &spf13cobra.Command{Use: "pagertest"}
+			cmd.Flags().Bool(noPagerFlagName, false, "")
+			if tt.noPager {
+				_ = cmd.Flags().Set(noPagerFlagName, "true")
+			}
+			cmd.SetOut(out)
+
+			if err := writePaged(cmd, tt.output); err != nil {
+				t.Fatal(err)
+			}
+			data, err := os.ReadFile(out.Name())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := string(data); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
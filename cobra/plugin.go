@@ -0,0 +1,476 @@
+package cobra
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	spf13cobra "github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// PluginDescribeFlag 插件自描述握手使用的参数
+//
+// 插件以该参数作为唯一参数运行时，应向标准输出写入 PluginDescription 的 JSON 并以 0 退出。
+// 使用 cobrax 编写的插件会自动处理该参数。
+const PluginDescribeFlag = "--cobrax-describe"
+
+// pluginAnnotation 记录插件可执行文件路径的命令注解
+const pluginAnnotation = "cobrax_plugin"
+
+// pluginDescribeTimeout 自描述握手的超时时间
+const pluginDescribeTimeout = 3 * time.Second
+
+// PluginDescription 插件命令树描述
+type PluginDescription struct {
+	Name     string              `json:"name"`
+	Short    string              `json:"short,omitempty"`
+	Long     string              `json:"long,omitempty"`
+	Flags    []PluginFlag        `json:"flags,omitempty"`
+	Commands []PluginDescription `json:"commands,omitempty"`
+}
+
+// PluginFlag 插件命令的 flag 描述
+type PluginFlag struct {
+	Name      string `json:"name"`
+	Shorthand string `json:"shorthand,omitempty"`
+	Usage     string `json:"usage,omitempty"`
+	Default   string `json:"default,omitempty"`
+	Type      string `json:"type,omitempty"`
+}
+
+// pluginSettings 插件设置
+type pluginSettings struct {
+	dirs      []string // 额外的插件目录，优先于 PATH
+	installed bool
+}
+
+// WithPlugins 启用外部插件发现（类似 git/kubectl）
+//
+// 名为 <root>-<name> 的可执行文件会作为 <name> 子命令挂载到根命令下，
+// 执行时原样转发参数、环境变量、标准输入输出和退出码。查找顺序为 dirs、
+// 用户配置目录下的 <root>/plugins 以及 PATH，同名插件以先找到的为准，内置命令优先于插件。
+//
+// 插件支持 --cobrax-describe 握手时，其返回的子命令和 flags 也会出现在 --tree、帮助和补全中。
+// 握手结果按可执行文件的修改时间缓存在用户缓存目录下的 <root>/plugins.json。
+// 插件只在显示帮助、树形视图、补全或参数无法解析为内置命令时才会发现，
+// 执行内置命令时不会扫描目录或运行任何插件。
+func WithPlugins(dirs ...string) CommandOption {
+	return func(c *Command) {
+		c.plugins = &pluginSettings{dirs: dirs}
+	}
+}
+
+// plugin 发现的插件
+type plugin struct {
+	name        string
+	path        string
+	description *PluginDescription // 握手失败时为 nil
+}
+
+// needPlugins 判断本次执行是否需要插件：帮助、树形视图、补全，或者参数无法解析为已有命令时
+//
+// 参数能解析为内置命令时不发现插件，避免在每次执行前扫描目录并运行插件握手。
+func (c *Command) needPlugins(args []string) bool {
+	if len(args) > 0 {
+		switch args[0] {
+		case "help", "completion", spf13cobra.ShellCompRequestCmd, spf13cobra.ShellCompNoDescRequestCmd:
+			return true
+		}
+	}
+	for _, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if arg == "-h" || arg == "--help" || strings.HasPrefix(arg, "--tree") {
+			return true
+		}
+	}
+	if os.Getenv("COBRA_TREE") == "true" {
+		return true
+	}
+
+	cmd, rest, err := c.Find(args)
+	if err != nil {
+		return true
+	}
+	if cmd != c.Command {
+		return false
+	}
+	// 停留在根命令上：根命令不可执行时会显示帮助，存在位置参数时可能是插件名称
+	return !c.Runnable() || c.hasPositionalArg(rest)
+}
+
+// hasPositionalArg 判断参数中是否包含位置参数，跳过根命令上已知 flag 的取值
+func (c *Command) hasPositionalArg(args []string) bool {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			return i+1 < len(args)
+		case !strings.HasPrefix(arg, "-") || arg == "-":
+			return true
+		case strings.Contains(arg, "="):
+			continue
+		}
+
+		var flag *pflag.Flag
+		if strings.HasPrefix(arg, "--") {
+			name := strings.TrimPrefix(arg, "--")
+			if flag = c.Flags().Lookup(name); flag == nil {
+				flag = c.PersistentFlags().Lookup(name)
+			}
+		} else if len(arg) == 2 {
+			if flag = c.Flags().ShorthandLookup(arg[1:]); flag == nil {
+				flag = c.PersistentFlags().ShorthandLookup(arg[1:])
+			}
+		}
+		if flag != nil && flag.NoOptDefVal == "" {
+			i++
+		}
+	}
+	return false
+}
+
+// installPlugins 发现插件并挂载到命令树
+func (c *Command) installPlugins() {
+	if c.plugins.installed {
+		return
+	}
+	c.plugins.installed = true
+
+	cache := loadPluginCache(c.Command)
+	for _, p := range c.discoverPlugins() {
+		// 内置命令优先，help 和 completion 由 cobra 在执行时添加
+		if findSubcommand(c.Command, p.name) != nil || p.name == "help" || p.name == "completion" {
+			continue
+		}
+		p.description = cache.describe(p.path)
+		pluginCmd := newPluginCommand(p, nil, p.description)
+		c.recordRegistration(pluginCmd)
+		c.Command.AddCommand(pluginCmd)
+	}
+	cache.save()
+}
+
+// searchDirs 返回插件查找目录
+func (s *pluginSettings) searchDirs(root *spf13cobra.Command) []string {
+	dirs := append([]string{}, s.dirs...)
+	if dir, err := os.UserConfigDir(); err == nil {
+		dirs = append(dirs, filepath.Join(dir, root.Name(), "plugins"))
+	}
+	return append(dirs, filepath.SplitList(os.Getenv("PATH"))...)
+}
+
+// discoverPlugins 在查找目录中发现 <root>-<name> 可执行文件
+func (c *Command) discoverPlugins() []*plugin {
+	prefix := c.Name() + "-"
+	seen := make(map[string]bool)
+
+	var plugins []*plugin
+	for _, dir := range c.plugins.searchDirs(c.Command) {
+		if dir == "" {
+			continue
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+
+		for _, entry := range entries {
+			name, ok := pluginName(entry.Name(), prefix)
+			if !ok || seen[name] {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			if !isExecutable(path) {
+				continue
+			}
+			seen[name] = true
+			plugins = append(plugins, &plugin{name: name, path: path})
+		}
+	}
+	return plugins
+}
+
+// pluginName 从文件名中解析插件名称
+func pluginName(file, prefix string) (string, bool) {
+	if runtime.GOOS == "windows" {
+		file = strings.TrimSuffix(file, filepath.Ext(file))
+	}
+	name := strings.TrimPrefix(file, prefix)
+	if name == file || name == "" || strings.HasPrefix(name, "-") {
+		return "", false
+	}
+	return name, true
+}
+
+// isExecutable 判断文件是否为可执行的普通文件
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		ext := strings.ToLower(filepath.Ext(path))
+		return ext == ".exe" || ext == ".bat" || ext == ".cmd"
+	}
+	return info.Mode().Perm()&0o111 != 0
+}
+
+// newPluginCommand 创建插件命令节点，path 为该节点在插件内部的子命令路径
+func newPluginCommand(p *plugin, path []string, desc *PluginDescription) *spf13cobra.Command {
+	cmd := &spf13cobra.Command{
+		Use:                p.name,
+		Short:              "Plugin provided by " + p.path,
+		DisableFlagParsing: true,
+		Annotations:        map[string]string{pluginAnnotation: p.path},
+		RunE: func(cmd *spf13cobra.Command, args []string) error {
+			return runPlugin(cmd, p.path, append(append([]string{}, path...), args...))
+		},
+	}
+	if desc == nil {
+		return cmd
+	}
+
+	if len(path) > 0 {
+		cmd.Use = desc.Name
+	}
+	if desc.Short != "" {
+		cmd.Short = desc.Short
+	}
+	cmd.Long = desc.Long
+	for _, flag := range desc.Flags {
+		definePluginFlag(cmd.Flags(), flag)
+	}
+
+	// 补全请求转发给插件自身
+	cmd.ValidArgsFunction = func(cmd *spf13cobra.Command, args []string, toComplete string) ([]string, spf13cobra.ShellCompDirective) {
+		return completePlugin(cmd, p.path, append(append(append([]string{}, path...), args...), toComplete))
+	}
+
+	for i := range desc.Commands {
+		child := &desc.Commands[i]
+		cmd.AddCommand(newPluginCommand(p, append(append([]string{}, path...), child.Name), child))
+	}
+	return cmd
+}
+
+// definePluginFlag 按描述注册 flag，仅用于帮助、树形视图和补全展示
+func definePluginFlag(fs *pflag.FlagSet, flag PluginFlag) {
+	if flag.Name == "" || fs.Lookup(flag.Name) != nil {
+		return
+	}
+	if flag.Shorthand != "" && fs.ShorthandLookup(flag.Shorthand) != nil {
+		flag.Shorthand = ""
+	}
+	fs.StringP(flag.Name, flag.Shorthand, flag.Default, flag.Usage)
+	if flag.Type == "bool" {
+		fs.Lookup(flag.Name).NoOptDefVal = "true"
+	}
+}
+
+// pluginEnv 返回传给插件的环境变量
+func pluginEnv(cmd *spf13cobra.Command) []string {
+	return append(os.Environ(),
+		"COBRAX_ROOT="+cmd.Root().Name(),
+		"COBRAX_COMMAND_PATH="+GetCommandFullPath(cmd),
+	)
+}
+
+// runPlugin 执行插件并转发标准输入输出与退出码
+func runPlugin(cmd *spf13cobra.Command, path string, args []string) error {
+	process := exec.CommandContext(commandContext(cmd), path, args...)
+	process.Stdin = cmd.InOrStdin()
+	process.Stdout = cmd.OutOrStdout()
+	process.Stderr = cmd.ErrOrStderr()
+	process.Env = pluginEnv(cmd)
+	// context 取消（中断或超时）时先发送中断信号，给插件留出退出时间
+	process.Cancel = func() error {
+		return process.Process.Signal(os.Interrupt)
+	}
+	process.WaitDelay = 5 * time.Second
+
+	err := process.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if ctxErr := commandContext(cmd).Err(); ctxErr != nil {
+			return ctxErr
+		}
+		// 插件已经自行输出了错误信息，这里只转发退出码（被信号终止时为 -1）
+		code := exitErr.ExitCode()
+		if code < 0 {
+			code = ExitCodeError
+		}
+		cmd.SilenceErrors = true
+		return &Error{
+			Category: CategoryGeneral,
+			Code:     code,
+			Message:  fmt.Sprintf("plugin %s exited with code %d", filepath.Base(path), code),
+		}
+	}
+	if err != nil {
+		return NewInternalError(fmt.Errorf("run plugin %s: %w", path, err))
+	}
+	return nil
+}
+
+// completePlugin 通过插件的 __complete 命令获取补全候选
+func completePlugin(cmd *spf13cobra.Command, path string, args []string) ([]string, spf13cobra.ShellCompDirective) {
+	ctx, cancel := context.WithTimeout(commandContext(cmd), pluginDescribeTimeout)
+	defer cancel()
+
+	process := exec.CommandContext(ctx, path, append([]string{spf13cobra.ShellCompRequestCmd}, args...)...)
+	process.Env = pluginEnv(cmd)
+	out, err := process.Output()
+	if err != nil {
+		return nil, spf13cobra.ShellCompDirectiveDefault
+	}
+
+	var completions []string
+	directive := spf13cobra.ShellCompDirectiveDefault
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if strings.HasPrefix(line, ":") {
+			var d int
+			if _, err := fmt.Sscanf(line, ":%d", &d); err == nil {
+				directive = spf13cobra.ShellCompDirective(d)
+			}
+			break
+		}
+		completions = append(completions, line)
+	}
+	return completions, directive
+}
+
+// describePlugin 执行插件的自描述握手
+func describePlugin(path string) (*PluginDescription, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), pluginDescribeTimeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, path, PluginDescribeFlag).Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var desc PluginDescription
+	if err := json.Unmarshal(out, &desc); err != nil {
+		return nil, fmt.Errorf("invalid plugin description: %w", err)
+	}
+	return &desc, nil
+}
+
+// DescribePlugin 生成命令树的插件描述（用于响应 --cobrax-describe 握手）
+func DescribePlugin(cmd *Command) PluginDescription {
+	cmd.materializeAll(cmd.Command)
+	return describeCommand(cmd.Command)
+}
+
+// describeCommand 递归生成命令描述，隐藏命令和内置 flag 不包含在内
+func describeCommand(cmd *spf13cobra.Command) PluginDescription {
+	desc := PluginDescription{
+		Name:  cmd.Name(),
+		Short: cmd.Short,
+		Long:  cmd.Long,
+	}
+
+	cmd.NonInheritedFlags().VisitAll(func(flag *pflag.Flag) {
+		if flag.Hidden || isBuiltinFlag(flag.Name) || flag.Name == "help" {
+			return
+		}
+		desc.Flags = append(desc.Flags, PluginFlag{
+			Name:      flag.Name,
+			Shorthand: flag.Shorthand,
+			Usage:     flag.Usage,
+			Default:   flag.DefValue,
+			Type:      flag.Value.Type(),
+		})
+	})
+
+	for _, child := range cmd.Commands() {
+		if !child.IsAvailableCommand() {
+			continue
+		}
+		desc.Commands = append(desc.Commands, describeCommand(child))
+	}
+	return desc
+}
+
+// handlePluginDescribe 以 --cobrax-describe 单独运行时输出命令树描述
+func (c *Command) handlePluginDescribe() bool {
+	if args := c.executeArgs(); len(args) != 1 || args[0] != PluginDescribeFlag {
+		return false
+	}
+	data, err := json.Marshal(DescribePlugin(c))
+	if err != nil {
+		return false
+	}
+	fmt.Fprintln(c.OutOrStdout(), string(data))
+	return true
+}
+
+// pluginCache 插件握手结果缓存
+type pluginCache struct {
+	path    string
+	entries map[string]pluginCacheEntry
+	dirty   bool
+}
+
+// pluginCacheEntry 单个插件的缓存项，可执行文件变化后失效
+type pluginCacheEntry struct {
+	ModTime     time.Time          `json:"modTime"`
+	Size        int64              `json:"size"`
+	Description *PluginDescription `json:"description,omitempty"`
+}
+
+// loadPluginCache 读取插件缓存
+func loadPluginCache(root *spf13cobra.Command) *pluginCache {
+	cache := &pluginCache{entries: make(map[string]pluginCacheEntry)}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return cache
+	}
+	cache.path = filepath.Join(dir, root.Name(), "plugins.json")
+	if data, err := os.ReadFile(cache.path); err == nil {
+		_ = json.Unmarshal(data, &cache.entries)
+	}
+	return cache
+}
+
+// describe 返回插件描述，缓存失效时重新握手
+func (pc *pluginCache) describe(path string) *PluginDescription {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil
+	}
+
+	if entry, ok := pc.entries[path]; ok && entry.ModTime.Equal(info.ModTime()) && entry.Size == info.Size() {
+		return entry.Description
+	}
+
+	desc, _ := describePlugin(path)
+	pc.entries[path] = pluginCacheEntry{ModTime: info.ModTime(), Size: info.Size(), Description: desc}
+	pc.dirty = true
+	return desc
+}
+
+// save 写回插件缓存
+func (pc *pluginCache) save() {
+	if !pc.dirty || pc.path == "" {
+		return
+	}
+	data, err := json.MarshalIndent(pc.entries, "", "  ")
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(pc.path), 0o755); err != nil {
+		return
+	}
+	_ = os.WriteFile(pc.path, data, 0o644)
+}
@@ -0,0 +1,55 @@
+package cobra
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestPluginsDiscoveredLazily(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin script requires a POSIX shell")
+	}
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("PATH", "")
+	t.Setenv("COBRA_TREE", "")
+
+	// 插件每次被执行都会在 marker 中追加一行
+	dir := t.TempDir()
+	marker := filepath.Join(dir, "marker")
+	script := "#!/bin/sh\necho \"$@\" >> " + marker + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "plugtest-hello"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(args ...string) {
+		t.Helper()
+		root := NewCommand("plugtest", WithPlugins(dir))
+		root.AddCommand(NewCommand("server", WithRun(func(cmd *Command, args []string) {})))
+		root.SetOut(&bytes.Buffer{})
+		root.SetErr(&bytes.Buffer{})
+		root.SetArgs(args)
+		if err := root.Execute(); err != nil {
+			t.Fatalf("Execute(%q) = %v", args, err)
+		}
+	}
+
+	// 内置命令不扫描也不执行插件
+	run("server")
+	if _, err := os.Stat(marker); !os.IsNotExist(err) {
+		t.Fatalf("plugin executed while running a built-in command")
+	}
+
+	// 无法解析为内置命令时发现并执行插件
+	run("hello", "world")
+	data, err := os.ReadFile(marker)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(data); got != PluginDescribeFlag+"\nworld\n" {
+		t.Errorf("plugin invocations = %q", got)
+	}
+}
@@ -0,0 +1,375 @@
+package cobra
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	spf13cobra "github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// profileFlagName 选择 profile 的 flag 名称
+const profileFlagName = "profile"
+
+// profileCommandAnnotation 标记内置 profile 管理命令的注解
+const profileCommandAnnotation = "cobrax_profile_command"
+
+// SourceProfile 来自 profile 预设
+const SourceProfile ValueSource = "profile"
+
+// profileSettings profile 设置
+type profileSettings struct {
+	path string // profiles 文件路径，为空时使用用户配置目录下的 profiles.json
+}
+
+// WithProfiles 启用 profile（命名的 flag 预设）支持
+//
+// 根命令会增加 --profile flag 和 profile list/create/show/delete 子命令，
+// profile 保存在用户配置目录下的 <root>/profiles.json 中：
+//
+//	{
+//	    "profiles": {
+//	        "prod": {
+//	            "endpoint": "https://api.example.com",
+//	            "myapp server": {"port": 443}
+//	        }
+//	    }
+//	}
+//
+// 与配置文件相同，顶层键对所有命令生效，以命令完整路径为键的对象只对该命令生效。
+// 显式选择的 profile 优先于配置文件，优先级为：命令行 > 环境变量 > profile > 配置文件 > 默认值。
+func WithProfiles() CommandOption {
+	return WithProfilesFile("")
+}
+
+// WithProfilesFile 启用 profile 支持并指定 profiles 文件路径
+func WithProfilesFile(path string) CommandOption {
+	return func(c *Command) {
+		c.profiles = &profileSettings{path: path}
+		if c.PersistentFlags().Lookup(profileFlagName) == nil {
+			c.PersistentFlags().String(profileFlagName, "", defaultMessages[MsgFlagProfile])
+			markFrameworkFlags(c.PersistentFlags(), profileFlagName)
+		}
+	}
+}
+
+// filePath 返回 profiles 文件路径
+func (s *profileSettings) filePath(root *spf13cobra.Command) (string, error) {
+	if s.path != "" {
+		return s.path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("locate profiles file: %w", err)
+	}
+	return filepath.Join(dir, root.Name(), "profiles.json"), nil
+}
+
+// profileStore profiles 文件内容
+type profileStore struct {
+	path     string
+	Profiles map[string]map[string]interface{} `json:"profiles"`
+}
+
+// load 读取 profiles 文件，文件不存在时返回空的存储
+func (s *profileSettings) load(root *spf13cobra.Command) (*profileStore, error) {
+	path, err := s.filePath(root)
+	if err != nil {
+		return nil, err
+	}
+
+	store := &profileStore{path: path, Profiles: make(map[string]map[string]interface{})}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read profiles file: %w", err)
+	}
+	if err := json.Unmarshal(data, store); err != nil {
+		return nil, fmt.Errorf("parse profiles file %s: %w", path, err)
+	}
+	if store.Profiles == nil {
+		store.Profiles = make(map[string]map[string]interface{})
+	}
+	return store, nil
+}
+
+// save 写回 profiles 文件（先写临时文件再重命名）
+func (s *profileStore) save() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("create profiles dir: %w", err)
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("write profiles file: %w", err)
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// names 返回排序后的 profile 名称
+func (s *profileStore) names() []string {
+	names := make([]string, 0, len(s.Profiles))
+	for name := range s.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// get 获取 profile，不存在时返回带可用列表的 NotFound 错误
+func (s *profileStore) get(name string) (map[string]interface{}, error) {
+	profile, ok := s.Profiles[name]
+	if !ok {
+		available := "none"
+		if names := s.names(); len(names) > 0 {
+			available = strings.Join(names, ", ")
+		}
+		return nil, NewNotFoundError("profile %q not found", name).
+			WithHint("available profiles: " + available)
+	}
+	return profile, nil
+}
+
+// profileLayer profile 取值层
+type profileLayer struct {
+	name   string
+	values *ConfigFile
+}
+
+func (l profileLayer) source() ValueSource {
+	return SourceProfile
+}
+
+func (l profileLayer) lookup(cmd *spf13cobra.Command, flag *pflag.Flag) (string, string, bool) {
+	if flag.Name == profileFlagName || flag.Name == configFlagName {
+		return "", "", false
+	}
+
+	value, section, ok := l.values.Lookup(cmd, flag.Name)
+	if !ok {
+		return "", "", false
+	}
+
+	detail := l.name
+	if section != "" {
+		detail += " [" + section + "]"
+	}
+	return value, detail, true
+}
+
+// profileValueLayer 根据 --profile 的当前取值构建 profile 取值层，未选择 profile 时返回 nil
+//
+// 内置的 profile 管理命令不应用 profile，所选 profile 不存在时仍可以创建或查看它。
+func (c *Command) profileValueLayer(cmd *spf13cobra.Command) (valueLayer, error) {
+	name := c.profileName(cmd)
+	if name == "" || isProfileCommand(cmd) {
+		return nil, nil
+	}
+
+	store, err := c.profiles.load(cmd.Root())
+	if err != nil {
+		return nil, err
+	}
+	profile, err := store.get(name)
+	if err != nil {
+		return nil, err
+	}
+	return profileLayer{name: name, values: &ConfigFile{Path: store.path, Values: profile}}, nil
+}
+
+// isProfileCommand 判断命令是否为内置的 profile 管理命令
+func isProfileCommand(cmd *spf13cobra.Command) bool {
+	for p := cmd; p != nil; p = p.Parent() {
+		if p.Annotations[profileCommandAnnotation] == "true" {
+			return true
+		}
+	}
+	return false
+}
+
+// profileName 返回 --profile 的取值（命令行或环境变量）
+func (c *Command) profileName(cmd *spf13cobra.Command) string {
+	return c.controlFlagValue(cmd, profileFlagName)
+}
+
+// activeProfile 获取当前生效的 profile 名称，未启用 profile 时返回空字符串
+//
+// --profile 是框架 flag，只来自命令行或环境变量，不需要运行完整的取值解析。
+func (c *Command) activeProfile(cmd *spf13cobra.Command) string {
+	if c.profiles == nil {
+		return ""
+	}
+	return c.profileName(cmd)
+}
+
+// installProfileCommands 在根命令下挂载 profile 管理子命令
+func (c *Command) installProfileCommands() {
+	for _, child := range c.Commands() {
+		if child.Name() == "profile" {
+			return
+		}
+	}
+
+	profileCmd := &spf13cobra.Command{
+		Use:         "profile",
+		Short:       defaultMessages[MsgCmdProfile],
+		Annotations: map[string]string{profileCommandAnnotation: "true"},
+	}
+
+	listCmd := &spf13cobra.Command{
+		Use:   "list",
+		Short: defaultMessages[MsgCmdProfileList],
+		Args:  spf13cobra.NoArgs,
+		RunE: func(cmd *spf13cobra.Command, args []string) error {
+			store, err := c.profiles.load(c.Command)
+			if err != nil {
+				return err
+			}
+
+			theme := c.getTreeConfig().Theme
+			active := c.activeProfile(cmd)
+			if len(store.Profiles) == 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), theme.LineStyle.Render("No profiles in "+store.path))
+				return nil
+			}
+			for _, name := range store.names() {
+				if name == active {
+					fmt.Fprintln(cmd.OutOrStdout(), theme.RootStyle.Render("* "+name))
+				} else {
+					fmt.Fprintln(cmd.OutOrStdout(), theme.LeafStyle.Render("  "+name))
+				}
+			}
+			return nil
+		},
+	}
+
+	createCmd := &spf13cobra.Command{
+		Use:     "create <name>",
+		Short:   defaultMessages[MsgCmdProfileCreate],
+		Example: "  " + c.Name() + " profile create prod --set endpoint=https://api.example.com --set port=443 --command server",
+		Args:    spf13cobra.ExactArgs(1),
+		RunE: func(cmd *spf13cobra.Command, args []string) error {
+			store, err := c.profiles.load(c.Command)
+			if err != nil {
+				return err
+			}
+
+			name := args[0]
+			force, _ := cmd.Flags().GetBool("force")
+			if _, exists := store.Profiles[name]; exists && !force {
+				return fmt.Errorf("profile %q already exists (use --force to overwrite)", name)
+			}
+
+			sets, _ := cmd.Flags().GetStringArray("set")
+			target, _ := cmd.Flags().GetString("command")
+
+			profile := make(map[string]interface{})
+			values := profile
+			if target != "" {
+				values = make(map[string]interface{})
+				profile[strings.TrimSpace(c.Name()+" "+target)] = values
+			}
+			for _, set := range sets {
+				key, value, ok := strings.Cut(set, "=")
+				if !ok || key == "" {
+					return fmt.Errorf("invalid --set %q, expected flag=value", set)
+				}
+				values[key] = value
+			}
+
+			store.Profiles[name] = profile
+			if err := store.save(); err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), c.getTreeConfig().Theme.LeafStyle.Render(fmt.Sprintf("Created profile %q in %s", name, store.path)))
+			return nil
+		},
+	}
+	createCmd.Flags().StringArray("set", nil, defaultMessages[MsgFlagProfileSet])
+	createCmd.Flags().String("command", "", defaultMessages[MsgFlagProfileCommand])
+	createCmd.Flags().Bool("force", false, defaultMessages[MsgFlagProfileForce])
+
+	showCmd := &spf13cobra.Command{
+		Use:   "show [name]",
+		Short: defaultMessages[MsgCmdProfileShow],
+		Args:  spf13cobra.MaximumNArgs(1),
+		RunE: func(cmd *spf13cobra.Command, args []string) error {
+			store, err := c.profiles.load(c.Command)
+			if err != nil {
+				return err
+			}
+
+			name := c.activeProfile(cmd)
+			if len(args) > 0 {
+				name = args[0]
+			}
+			if name == "" {
+				return fmt.Errorf("no profile selected, pass a name or use --%s", profileFlagName)
+			}
+			profile, err := store.get(name)
+			if err != nil {
+				return err
+			}
+
+			theme := c.getTreeConfig().Theme
+			fmt.Fprintln(cmd.OutOrStdout(), theme.RootStyle.Render("Profile: "+name))
+			printProfileValues(cmd, theme, profile, "  ")
+			return nil
+		},
+	}
+
+	deleteCmd := &spf13cobra.Command{
+		Use:   "delete <name>",
+		Short: defaultMessages[MsgCmdProfileDelete],
+		Args:  spf13cobra.ExactArgs(1),
+		RunE: func(cmd *spf13cobra.Command, args []string) error {
+			store, err := c.profiles.load(c.Command)
+			if err != nil {
+				return err
+			}
+			if _, err := store.get(args[0]); err != nil {
+				return err
+			}
+
+			delete(store.Profiles, args[0])
+			if err := store.save(); err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), c.getTreeConfig().Theme.LeafStyle.Render(fmt.Sprintf("Deleted profile %q", args[0])))
+			return nil
+		},
+	}
+
+	profileCmd.AddCommand(listCmd, createCmd, showCmd, deleteCmd)
+	c.Command.AddCommand(profileCmd)
+}
+
+// printProfileValues 按键排序输出 profile 中的值，命令段缩进显示
+func printProfileValues(cmd *spf13cobra.Command, theme *TreeTheme, values map[string]interface{}, indent string) {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if section, ok := values[key].(map[string]interface{}); ok {
+			fmt.Fprintln(cmd.OutOrStdout(), indent+theme.BranchStyle.Render("["+key+"]"))
+			printProfileValues(cmd, theme, section, indent+"  ")
+			continue
+		}
+		value, _ := configValueString(values[key])
+		fmt.Fprintln(cmd.OutOrStdout(), indent+theme.FlagStyle.Render(key)+" = "+theme.LeafStyle.Render(value))
+	}
+}
@@ -0,0 +1,95 @@
+package cobra
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestProfileCommandsWithMissingProfile(t *testing.T) {
+	t.Setenv("COBRA_TREE", "")
+	profilesPath := filepath.Join(t.TempDir(), "profiles.json")
+
+	tests := []struct {
+		name string
+		env  map[string]string
+		args []string
+		want string
+	}{
+		{"create selected by flag", nil, []string{"--profile", "staging", "profile", "create", "staging", "--set", "name=x"}, `Created profile "staging"`},
+		{"list selected by env", map[string]string{"APP_PROFILE": "qa"}, []string{"profile", "list"}, "staging"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			root := NewCommand("app",
+				WithEnvPrefix("APP"),
+				WithProfilesFile(profilesPath),
+				WithFlags(func(flags *FlagSet) { flags.String("name", "", "Name") }),
+				WithRun(func(cmd *Command, args []string) {}),
+			)
+			var stdout bytes.Buffer
+			root.SetOut(&stdout)
+			root.SetErr(&bytes.Buffer{})
+			root.SetArgs(tt.args)
+			if err := root.Execute(); err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(stdout.String(), tt.want) {
+				t.Errorf("output %q does not contain %q", stdout.String(), tt.want)
+			}
+		})
+	}
+
+	if _, err := os.Stat(profilesPath); err != nil {
+		t.Errorf("profiles file not written: %v", err)
+	}
+}
+
+func TestProfileOverridesConfigFile(t *testing.T) {
+	t.Setenv("COBRA_TREE", "")
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "app.json")
+	if err := os.WriteFile(configPath, []byte(`{"port": 7000}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	profilesPath := filepath.Join(dir, "profiles.json")
+	if err := os.WriteFile(profilesPath, []byte(`{"profiles": {"prod": {"port": 443}}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{"config only", []string{"--config", configPath}, 7000},
+		{"profile over config", []string{"--config", configPath, "--profile", "prod"}, 443},
+		{"flag over profile", []string{"--config", configPath, "--profile", "prod", "--port", "8080"}, 8080},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got int
+			root := NewCommand("app",
+				WithConfigFile("app", t.TempDir()),
+				WithProfilesFile(profilesPath),
+				WithFlags(func(flags *FlagSet) { flags.Int("port", 80, "Port") }),
+				WithRun(func(cmd *Command, args []string) { got, _ = cmd.Flags().GetInt("port") }),
+			)
+			root.SetOut(&bytes.Buffer{})
+			root.SetErr(&bytes.Buffer{})
+			root.SetArgs(tt.args)
+			if err := root.Execute(); err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("--port = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
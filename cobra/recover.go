@@ -0,0 +1,191 @@
+package cobra
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	spf13cobra "github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// ExitCodePanic 执行过程中发生 panic 时的退出码
+const ExitCodePanic = 101
+
+// redactedValue 敏感参数的替换值
+const redactedValue = "[REDACTED]"
+
+// secretFlagPattern 匹配需要脱敏的 flag 名称
+var secretFlagPattern = regexp.MustCompile(`(?i)(pass(word|wd)?|secret|token|api[-_]?key|private[-_]?key|credential|auth)`)
+
+// recoverySettings panic 恢复设置
+type recoverySettings struct {
+	dir string // 崩溃报告目录，为空时使用用户缓存目录下的 <root>/crash
+}
+
+// WithPanicRecovery 启用 panic 恢复
+//
+// 执行函数发生 panic 时不再输出原始的 goroutine 堆栈，而是输出简短的错误信息，
+// 并把崩溃报告（命令路径、脱敏后的参数、版本信息、堆栈、Go 运行时信息）写入 dir，
+// 最终返回退出码为 ExitCodePanic 的 *Error。dir 为空时使用 $XDG_CACHE_HOME/<root>/crash。
+func WithPanicRecovery(dir string) CommandOption {
+	return func(c *Command) {
+		c.recovery = &recoverySettings{dir: dir}
+	}
+}
+
+// crashDir 返回崩溃报告目录
+func (s *recoverySettings) crashDir(root *spf13cobra.Command) (string, error) {
+	if s.dir != "" {
+		return s.dir, nil
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, root.Name(), "crash"), nil
+}
+
+// executeC 执行命令，启用 panic 恢复时捕获执行过程中的 panic
+func (c *Command) executeC() (cmd *spf13cobra.Command, err error) {
+	if c.recovery == nil {
+		return c.Command.ExecuteC()
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			cmd, err = c.recoverPanic(r, debug.Stack())
+		}
+	}()
+	return c.Command.ExecuteC()
+}
+
+// recoverPanic 写入崩溃报告并将 panic 转换为错误
+func (c *Command) recoverPanic(value interface{}, stack []byte) (*spf13cobra.Command, error) {
+	cmd := c.current
+	args := c.currentArgs
+	if cmd == nil {
+		cmd = c.Command
+		args = c.executeArgs()
+		// 参数无法完整解析时以找到的最深命令判断 flag 是否取值
+		found, _, _ := c.Find(args)
+		if found == nil {
+			found = c.Command
+		}
+		args = redactArgs(found, args)
+	}
+
+	path := GetCommandFullPath(cmd)
+	e := &Error{
+		Category: CategoryInternal,
+		Code:     ExitCodePanic,
+		Message:  fmt.Sprintf("%s crashed unexpectedly: %v", path, value),
+	}
+
+	report := buildCrashReport(path, args, c.GetBuildInfo(), value, stack)
+	file, err := c.writeCrashReport(report)
+	if err != nil {
+		e.Hint = "failed to write crash report: " + err.Error()
+	} else {
+		e.Hint = "a crash report was written to " + file
+	}
+	return cmd, e
+}
+
+// buildCrashReport 生成崩溃报告内容
+func buildCrashReport(path string, args []string, info BuildInfo, value interface{}, stack []byte) string {
+	var builder strings.Builder
+	builder.WriteString("Crash Report\n")
+	builder.WriteString("============\n\n")
+	fmt.Fprintf(&builder, "Time:       %s\n", time.Now().Format(time.RFC3339))
+	fmt.Fprintf(&builder, "Command:    %s\n", path)
+	fmt.Fprintf(&builder, "Args:       %s\n", strings.Join(args, " "))
+	fmt.Fprintf(&builder, "Version:    %s\n", info.Version)
+	fmt.Fprintf(&builder, "Git Commit: %s\n", info.GitCommit)
+	fmt.Fprintf(&builder, "Tree State: %s\n", info.GitTreeState)
+	fmt.Fprintf(&builder, "Build Date: %s\n", info.BuildDate)
+	fmt.Fprintf(&builder, "Cobrax:     %s\n", info.CobraxVersion)
+	builder.WriteString("\nRuntime\n-------\n")
+	fmt.Fprintf(&builder, "Go:         %s\n", info.GoVersion)
+	fmt.Fprintf(&builder, "Platform:   %s\n", info.Platform)
+	fmt.Fprintf(&builder, "CPUs:       %d (GOMAXPROCS=%d)\n", runtime.NumCPU(), runtime.GOMAXPROCS(0))
+	fmt.Fprintf(&builder, "Goroutines: %d\n", runtime.NumGoroutine())
+	fmt.Fprintf(&builder, "\nPanic: %v\n\nStack\n-----\n%s", value, stack)
+	return builder.String()
+}
+
+// writeCrashReport 将崩溃报告写入文件，返回文件路径
+func (c *Command) writeCrashReport(report string) (string, error) {
+	dir, err := c.recovery.crashDir(c.Command)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", err
+	}
+
+	name := fmt.Sprintf("crash-%s-%s-%d.txt", c.Name(), time.Now().Format("20060102-150405"), os.Getpid())
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(report), 0o600); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// commandArgs 根据解析后的 flag 还原命令参数，敏感 flag 的值会被脱敏
+func commandArgs(cmd *spf13cobra.Command, args []string) []string {
+	var result []string
+	cmd.Flags().Visit(func(flag *pflag.Flag) {
+		value := flag.Value.String()
+		if secretFlagPattern.MatchString(flag.Name) {
+			value = redactedValue
+		}
+		result = append(result, "--"+flag.Name+"="+value)
+	})
+	return append(result, args...)
+}
+
+// redactArgs 对原始命令行参数脱敏（--token=xxx 与 --token xxx 两种形式）
+//
+// 只有取值的 flag 才会把下一个参数当作值脱敏，--token-refresh 这类 bool flag 不影响后续参数；
+// cmd 上找不到定义的 flag 无法判断是否取值，按取值处理。
+func redactArgs(cmd *spf13cobra.Command, args []string) []string {
+	result := make([]string, len(args))
+	redactNext := false
+	for i, arg := range args {
+		switch {
+		case redactNext:
+			result[i] = redactedValue
+			redactNext = false
+		case strings.HasPrefix(arg, "-"):
+			name, _, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+			if secretFlagPattern.MatchString(name) {
+				if hasValue {
+					result[i] = arg[:strings.Index(arg, "=")+1] + redactedValue
+				} else {
+					result[i] = arg
+					redactNext = flagTakesValue(cmd, name)
+				}
+				continue
+			}
+			result[i] = arg
+		default:
+			result[i] = arg
+		}
+	}
+	return result
+}
+
+// flagTakesValue 判断命令（及其父命令的持久化 flag）上名为 name 的 flag 是否需要取值
+func flagTakesValue(cmd *spf13cobra.Command, name string) bool {
+	flag := cmd.Flags().Lookup(name)
+	for p := cmd; flag == nil && p != nil; p = p.Parent() {
+		flag = p.PersistentFlags().Lookup(name)
+	}
+	return flag == nil || flag.NoOptDefVal == ""
+}
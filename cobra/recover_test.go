@@ -0,0 +1,147 @@
+package cobra
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	spf13cobra "github.com/spf13/cobra"
+)
+
+func TestPanicRecovery(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    []string // 崩溃报告中应包含的内容
+		notWant []string
+	}{
+		{
+			name: "panic in run",
+			args: []string{"serve", "--token", "s3cret", "--port", "80"},
+			want: []string{
+				"Command:    crashtest serve\n",
+				"Args:       --port=80 --token=" + redactedValue + "\n",
+				"Version:    1.2.3\n",
+				"Panic: boom in run\n",
+				"Stack\n-----\n",
+			},
+			notWant: []string{"s3cret"},
+		},
+		{
+			name: "panic before hooks",
+			args: []string{"serve", "--token-refresh", "--api-key", "s3cret", "args-panic"},
+			want: []string{
+				"Command:    crashtest\n",
+				"Args:       serve --token-refresh --api-key " + redactedValue + " args-panic\n",
+				"Panic: boom in args\n",
+			},
+			notWant: []string{"s3cret"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("COBRA_TREE", "")
+			dir := t.TempDir()
+
+			serve := NewCommand("serve",
+				WithArgs(func(cmd *spf13cobra.Command, args []string) error {
+					if len(args) > 0 && args[0] == "args-panic" {
+						panic("boom in args")
+					}
+					return nil
+				}),
+				WithRun(func(cmd *Command, args []string) { panic("boom in run") }),
+			)
+			serve.Flags().String("token", "", "token")
+			serve.Flags().Bool("token-refresh", false, "refresh the token")
+			serve.Flags().String("api-key", "", "api key")
+			serve.Flags().Int("port", 8080, "port")
+			root := NewCommand("crashtest",
+				WithPanicRecovery(dir),
+				WithVersion("1.2.3"),
+				WithSubcommands(serve),
+			)
+			var stderr bytes.Buffer
+			root.SetOut(&bytes.Buffer{})
+			root.SetErr(&stderr)
+			root.SetArgs(tt.args)
+
+			err := root.Execute()
+			var e *Error
+			if !errors.As(err, &e) || e.Category != CategoryInternal {
+				t.Fatalf("Execute() = %#v, want an internal *Error", err)
+			}
+			if got := ExitCode(err); got != ExitCodePanic {
+				t.Errorf("ExitCode() = %d, want %d", got, ExitCodePanic)
+			}
+			if strings.Contains(stderr.String(), "goroutine ") {
+				t.Errorf("raw stack trace printed: %q", stderr.String())
+			}
+
+			files, _ := filepath.Glob(filepath.Join(dir, "crash-crashtest-*.txt"))
+			if len(files) != 1 {
+				t.Fatalf("crash reports = %v, want exactly one", files)
+			}
+			if !strings.Contains(e.Hint, files[0]) {
+				t.Errorf("hint = %q, want the report path %q", e.Hint, files[0])
+			}
+			data, err := os.ReadFile(files[0])
+			if err != nil {
+				t.Fatal(err)
+			}
+			report := string(data)
+			for _, s := range tt.want {
+				if !strings.Contains(report, s) {
+					t.Errorf("report missing %q:\n%s", s, report)
+				}
+			}
+			for _, s := range tt.notWant {
+				if strings.Contains(report, s) {
+					t.Errorf("report contains %q:\n%s", s, report)
+				}
+			}
+		})
+	}
+}
+
+func TestRedactArgs(t *testing.T) {
+	root := NewCommand("redacttest")
+	root.PersistentFlags().String("auth", "", "auth header")
+	sub := NewCommand("sub")
+	sub.Flags().Bool("token-refresh", false, "refresh the token")
+	sub.Flags().String("password", "", "password")
+	root.AddCommand(sub)
+
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{
+			[]string{"sub", "--password", "p", "--password=p"},
+			[]string{"sub", "--password", redactedValue, "--password=" + redactedValue},
+		},
+		{
+			[]string{"sub", "--token-refresh", "file.txt"},
+			[]string{"sub", "--token-refresh", "file.txt"},
+		},
+		{
+			[]string{"sub", "--auth", "a"},
+			[]string{"sub", "--auth", redactedValue},
+		},
+		{
+			// 未定义的 flag 按取值处理
+			[]string{"sub", "--secret", "s"},
+			[]string{"sub", "--secret", redactedValue},
+		},
+	}
+	for _, tt := range tests {
+		if got := redactArgs(sub.Command, tt.args); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("redactArgs(%q) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
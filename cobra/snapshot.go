@@ -0,0 +1,410 @@
+package cobra
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	spf13cobra "github.com/spf13/cobra"
+)
+
+// TreeSnapshotFlag 生成命令树快照的参数：<root> --tree-snapshot <file>（"-" 表示标准输出）
+const TreeSnapshotFlag = "--tree-snapshot"
+
+// treeSnapshotFormat 快照格式版本，格式变化时旧快照失效
+const treeSnapshotFormat = 2
+
+// TreeSnapshot 预先计算的命令树快照
+//
+// 渲染（RenderTree、RenderFlatTree）和补全可以直接使用快照，无需构建完整的命令树。
+type TreeSnapshot struct {
+	Format int              `json:"format"`
+	Binary string           `json:"binary,omitempty"` // 生成快照的可执行文件标识，构建时生成的快照为空
+	Root   *TreeDisplayNode `json:"root"`
+}
+
+// snapshotSettings 快照设置
+type snapshotSettings struct {
+	data []byte // 构建时生成并嵌入的快照
+
+	once     sync.Once
+	snapshot *TreeSnapshot
+	key      string // 运行时缓存的键
+}
+
+// WithTreeSnapshot 启用命令树快照
+//
+// data 为构建时生成并通过 go:embed 嵌入的快照，为空时在首次需要完整命令树（如 --tree）时生成快照，
+// 并以可执行文件的哈希为键缓存在用户缓存目录下的 <root>/tree-<hash>.json。
+// 启用后 --tree 和 shell 补全（子命令与 flag 名称）直接使用快照：
+//
+//	//go:generate go run . --tree-snapshot tree.snapshot.json
+//	//go:embed tree.snapshot.json
+//	var treeSnapshot []byte
+//
+//	rootCmd := cobra.NewCommand("myapp", cobra.WithTreeSnapshot(treeSnapshot))
+func WithTreeSnapshot(data []byte) CommandOption {
+	return func(c *Command) {
+		c.snapshot = &snapshotSettings{data: data}
+	}
+}
+
+// BuildTreeSnapshot 构建命令树快照（会构建所有懒加载命令）
+func BuildTreeSnapshot(root *Command) *TreeSnapshot {
+	prepareDisplayTree(root)
+	return &TreeSnapshot{
+		Format: treeSnapshotFormat,
+		Root:   buildDisplayTree(root, "", 0),
+	}
+}
+
+// LoadTreeSnapshot 解析快照
+func LoadTreeSnapshot(data []byte) (*TreeSnapshot, error) {
+	var snapshot TreeSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("invalid tree snapshot: %w", err)
+	}
+	if snapshot.Format != treeSnapshotFormat || snapshot.Root == nil {
+		return nil, fmt.Errorf("unsupported tree snapshot format %d", snapshot.Format)
+	}
+	return &snapshot, nil
+}
+
+// WriteTo 将快照以 JSON 写入 w
+func (s *TreeSnapshot) WriteTo(w io.Writer) (int64, error) {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(append(data, '\n'))
+	return int64(n), err
+}
+
+// Find 根据命令路径（如 "myapp server start"）查找节点
+func (s *TreeSnapshot) Find(path string) *TreeDisplayNode {
+	names := strings.Fields(path)
+	if len(names) == 0 || names[0] != s.Root.Name {
+		return nil
+	}
+
+	node := s.Root
+	for _, name := range names[1:] {
+		if node = node.child(name); node == nil {
+			return nil
+		}
+	}
+	return node
+}
+
+// child 按名称查找子节点
+func (n *TreeDisplayNode) child(name string) *TreeDisplayNode {
+	for _, child := range n.Children {
+		if child.Name == name {
+			return child
+		}
+	}
+	return nil
+}
+
+// displayNode 返回命令的显示树：根命令启用快照时使用快照，否则构建命令树
+func (c *Command) displayNode() *TreeDisplayNode {
+	if root := c.rootCommand(); root.snapshot != nil {
+		if snapshot := root.treeSnapshot(); snapshot != nil {
+			if node := snapshot.Find(c.CommandPath()); node != nil {
+				return node
+			}
+		}
+	}
+
+	prepareDisplayTree(c)
+	return buildDisplayTree(c, "", 0)
+}
+
+// prepareDisplayTree 构建命令下所有的懒加载命令，为新构建的 flag 记录环境变量名并翻译
+func prepareDisplayTree(cmd *Command) {
+	cmd.materializeAll(cmd.Command)
+	root := cmd.rootCommand()
+	if root.envPrefix != "" {
+		annotateEnvFlags(root.Command, root.envPrefix)
+	}
+	root.localize()
+}
+
+// treeSnapshot 加载快照：优先使用嵌入的快照，其次是运行时缓存，都没有时生成并写入缓存
+func (c *Command) treeSnapshot() *TreeSnapshot {
+	s := c.snapshot
+	s.once.Do(func() {
+		if c.useEmbeddedSnapshot() {
+			if snapshot, err := LoadTreeSnapshot(s.data); err == nil {
+				s.snapshot = snapshot
+				return
+			}
+		}
+
+		path, err := c.snapshotCachePath()
+		if err != nil {
+			return
+		}
+		if data, err := os.ReadFile(path); err == nil {
+			if snapshot, err := LoadTreeSnapshot(data); err == nil && snapshot.Binary == s.key {
+				s.snapshot = snapshot
+				return
+			}
+		}
+
+		s.snapshot = BuildTreeSnapshot(c)
+		s.snapshot.Binary = s.key
+		writeSnapshotCache(path, c.Locale(), s.snapshot)
+	})
+	return s.snapshot
+}
+
+// cachedTreeSnapshot 返回已有的快照（嵌入或缓存），不存在时返回 nil 而不生成
+func (c *Command) cachedTreeSnapshot() *TreeSnapshot {
+	if !c.useEmbeddedSnapshot() {
+		path, err := c.snapshotCachePath()
+		if err != nil {
+			return nil
+		}
+		if _, err := os.Stat(path); err != nil {
+			return nil
+		}
+	}
+	return c.treeSnapshot()
+}
+
+// useEmbeddedSnapshot 是否使用嵌入的快照
+//
+// 插件在运行时发现，嵌入的快照中不包含插件，因此启用插件时只使用运行时缓存；
+// 嵌入的快照使用默认语言生成，使用其它语言时同样只使用运行时缓存。
+func (c *Command) useEmbeddedSnapshot() bool {
+	return len(c.snapshot.data) > 0 && c.plugins == nil && c.catalog() == nil
+}
+
+// snapshotCachePath 返回运行时快照缓存路径
+func (c *Command) snapshotCachePath() (string, error) {
+	if c.snapshot.key == "" {
+		key, err := c.snapshotKey()
+		if err != nil {
+			return "", err
+		}
+		c.snapshot.key = key
+	}
+
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, c.Name(), snapshotCachePrefix(c.Locale())+c.snapshot.key+".json"), nil
+}
+
+// snapshotCachePrefix 返回某个语言的快照缓存文件名前缀，不同语言的缓存互不影响
+func snapshotCachePrefix(locale string) string {
+	if locale == "" {
+		locale = "default"
+	}
+	locale = strings.Map(func(r rune) rune {
+		if r == '-' || r == '_' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' {
+			return r
+		}
+		return '_'
+	}, locale)
+	return "tree-" + locale + "-"
+}
+
+// snapshotKey 计算快照缓存的键：可执行文件的路径、大小和修改时间以及当前语言，
+// 启用插件时还包含发现的插件的路径和修改时间
+//
+// 补全时每次按键都会计算该键，因此只读取文件元数据而不对可执行文件内容做哈希。
+func (c *Command) snapshotKey() (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	info, err := os.Stat(exe)
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s %d %d\n", exe, info.Size(), info.ModTime().UnixNano())
+
+	if locale := c.Locale(); locale != "" {
+		fmt.Fprintf(hash, "lang %s\n", locale)
+	}
+	if c.plugins != nil {
+		for _, p := range c.discoverPlugins() {
+			if info, err := os.Stat(p.path); err == nil {
+				fmt.Fprintf(hash, "%s %d %d\n", p.path, info.Size(), info.ModTime().UnixNano())
+			}
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil))[:16], nil
+}
+
+// writeSnapshotCache 写入快照缓存，并清理同一语言的旧快照，失败时忽略
+func writeSnapshotCache(path, locale string, snapshot *TreeSnapshot) {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return
+	}
+
+	// 键不含 "-"，据此区分 zh 与 zh-CN 等前缀相同的语言
+	prefix := snapshotCachePrefix(locale)
+	old, _ := filepath.Glob(filepath.Join(dir, prefix+"*.json"))
+	for _, file := range old {
+		key := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(file), prefix), ".json")
+		if file != path && !strings.Contains(key, "-") {
+			_ = os.Remove(file)
+		}
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+	_, _ = snapshot.WriteTo(file)
+}
+
+// handleSnapshotRequest 处理 --tree-snapshot <file>，生成构建时使用的快照
+func (c *Command) handleSnapshotRequest() (bool, error) {
+	args := c.executeArgs()
+	if c.snapshot == nil || len(args) == 0 {
+		return false, nil
+	}
+
+	var target string
+	switch {
+	case args[0] == TreeSnapshotFlag && len(args) == 2:
+		target = args[1]
+	case strings.HasPrefix(args[0], TreeSnapshotFlag+"=") && len(args) == 1:
+		target = strings.TrimPrefix(args[0], TreeSnapshotFlag+"=")
+	default:
+		return false, nil
+	}
+
+	// 构建时生成的快照总是使用默认语言
+	if c.i18n != nil {
+		c.setLocale("")
+	}
+	snapshot := BuildTreeSnapshot(c)
+	if target == "-" {
+		_, err := snapshot.WriteTo(c.OutOrStdout())
+		return true, err
+	}
+
+	file, err := os.Create(target)
+	if err != nil {
+		return true, err
+	}
+	defer file.Close()
+	_, err = snapshot.WriteTo(file)
+	return true, err
+}
+
+// completeFromSnapshot 使用快照响应子命令和 flag 名称的补全请求
+//
+// 只处理能够完全由快照确定的情况（补全子命令名称或 flag 名称），
+// 其余情况（位置参数、flag 的值等）返回 false，交给 cobra 的补全逻辑处理。
+func (c *Command) completeFromSnapshot(args []string) bool {
+	if c.snapshot == nil || len(args) < 2 {
+		return false
+	}
+	withDescriptions := args[0] == spf13cobra.ShellCompRequestCmd
+	if !withDescriptions && args[0] != spf13cobra.ShellCompNoDescRequestCmd {
+		return false
+	}
+
+	snapshot := c.cachedTreeSnapshot()
+	if snapshot == nil {
+		return false
+	}
+
+	words, toComplete := args[1:len(args)-1], args[len(args)-1]
+	node := snapshot.Root
+	var inherited []FlagDisplayInfo
+	expectValue := false
+	for _, word := range words {
+		switch {
+		case expectValue:
+			expectValue = false
+		case word == "--":
+			return false
+		case strings.HasPrefix(word, "-"):
+			if strings.Contains(word, "=") {
+				continue
+			}
+			flag := findSnapshotFlag(append(node.Flags, inherited...), word)
+			if flag == nil {
+				return false
+			}
+			expectValue = flag.Type != "bool" && flag.Type != "count"
+		default:
+			child := node.child(word)
+			if child == nil {
+				return false
+			}
+			for _, flag := range node.Flags {
+				if flag.Persistent {
+					inherited = append(inherited, flag)
+				}
+			}
+			node = child
+		}
+	}
+	if expectValue {
+		return false
+	}
+
+	var candidates []string
+	if strings.HasPrefix(toComplete, "-") {
+		for _, flag := range append(node.Flags, inherited...) {
+			if name := "--" + flag.Name; strings.HasPrefix(name, toComplete) {
+				candidates = append(candidates, completionLine(name, flag.Description, withDescriptions))
+			}
+		}
+	} else {
+		if len(node.Children) == 0 || node.IsRunnable {
+			return false
+		}
+		for _, child := range node.Children {
+			if strings.HasPrefix(child.Name, toComplete) {
+				candidates = append(candidates, completionLine(child.Name, child.Description, withDescriptions))
+			}
+		}
+	}
+
+	out := c.OutOrStdout()
+	for _, candidate := range candidates {
+		fmt.Fprintln(out, candidate)
+	}
+	fmt.Fprintf(out, ":%d\n", spf13cobra.ShellCompDirectiveNoFileComp)
+	fmt.Fprintln(c.ErrOrStderr(), "Completion ended with directive: ShellCompDirectiveNoFileComp")
+	return true
+}
+
+// findSnapshotFlag 按 --name 或 -s 查找 flag
+func findSnapshotFlag(flags []FlagDisplayInfo, word string) *FlagDisplayInfo {
+	for i, flag := range flags {
+		if word == "--"+flag.Name || (flag.ShortName != "" && word == "-"+flag.ShortName) {
+			return &flags[i]
+		}
+	}
+	return nil
+}
+
+// completionLine 生成一行补全候选
+func completionLine(value, description string, withDescription bool) string {
+	if !withDescription || description == "" {
+		return value
+	}
+	description, _, _ = strings.Cut(description, "\n")
+	return value + "\t" + description
+}
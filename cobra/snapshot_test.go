@@ -0,0 +1,31 @@
+package cobra
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteSnapshotCacheKeepsOtherLocales(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"tree-default-aaaa.json", "tree-zh-CN-bbbb.json", "tree-zh-cccc.json"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("{}"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	path := filepath.Join(dir, snapshotCachePrefix("zh")+"dddd.json")
+	writeSnapshotCache(path, "zh", &TreeSnapshot{Format: treeSnapshotFormat, Root: &TreeDisplayNode{Name: "app"}})
+
+	for name, want := range map[string]bool{
+		"tree-default-aaaa.json": true,
+		"tree-zh-CN-bbbb.json":   true,
+		"tree-zh-cccc.json":      false,
+		"tree-zh-dddd.json":      true,
+	} {
+		_, err := os.Stat(filepath.Join(dir, name))
+		if got := err == nil; got != want {
+			t.Errorf("%s exists = %v, want %v", name, got, want)
+		}
+	}
+}
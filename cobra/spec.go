@@ -0,0 +1,427 @@
+package cobra
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// CommandSpec 命令的声明式定义，通常从 JSON 规格文件加载
+//
+//	{
+//	  "name": "myapp",
+//	  "short": "My application",
+//	  "groups": [{"id": "manage", "title": "Management Commands"}],
+//	  "commands": [
+//	    {
+//	      "name": "config",
+//	      "group": "manage",
+//	      "short": "Manage configuration",
+//	      "commands": [
+//	        {
+//	          "name": "init",
+//	          "short": "Initialize configuration",
+//	          "flags": [{"name": "force", "shorthand": "f", "type": "bool", "usage": "Force overwrite"}]
+//	        }
+//	      ]
+//	    }
+//	  ]
+//	}
+//
+// 没有子命令的节点（或显式设置 "runnable": true 的节点）为可执行命令，必须在 HandlerRegistry 中注册处理函数。
+type CommandSpec struct {
+	Name       string         `json:"name"`
+	Aliases    []string       `json:"aliases,omitempty"`
+	Short      string         `json:"short,omitempty"`
+	Long       string         `json:"long,omitempty"`
+	Example    string         `json:"example,omitempty"`
+	Group      string         `json:"group,omitempty"`  // 所属分组，必须在父节点的 groups 中声明
+	Groups     []TreeGroup    `json:"groups,omitempty"` // 子命令分组
+	Hidden     bool           `json:"hidden,omitempty"`
+	Deprecated string         `json:"deprecated,omitempty"`
+	Runnable   bool           `json:"runnable,omitempty"` // 有子命令的节点本身也可执行
+	Flags      []FlagSpec     `json:"flags,omitempty"`
+	Commands   []*CommandSpec `json:"commands,omitempty"`
+}
+
+// FlagSpec flag 的声明式定义
+type FlagSpec struct {
+	Name       string          `json:"name"`
+	Shorthand  string          `json:"shorthand,omitempty"`
+	Type       string          `json:"type,omitempty"`    // string（默认）, bool, int, int64, float64, duration, count, stringSlice
+	Default    json.RawMessage `json:"default,omitempty"` // 与类型对应的 JSON 值，duration 使用字符串（如 "30s"）
+	Usage      string          `json:"usage,omitempty"`
+	Persistent bool            `json:"persistent,omitempty"`
+	Required   bool            `json:"required,omitempty"`
+	Hidden     bool            `json:"hidden,omitempty"`
+}
+
+// SpecError 规格校验错误（聚合所有问题）
+type SpecError struct {
+	Problems []SpecProblem
+}
+
+// SpecProblem 单个规格问题
+type SpecProblem struct {
+	Path    string // 命令路径（不含根命令名称），根命令为空
+	Message string
+}
+
+// Error 实现 error 接口
+func (e *SpecError) Error() string {
+	lines := make([]string, 0, len(e.Problems)+1)
+	lines = append(lines, "invalid command spec ("+e.count()+"):")
+	for _, p := range e.Problems {
+		path := p.Path
+		if path == "" {
+			path = "<root>"
+		}
+		lines = append(lines, fmt.Sprintf("  %s: %s", path, p.Message))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// count 返回问题数量的描述，如 "1 problem"、"2 problems"
+func (e *SpecError) count() string {
+	if len(e.Problems) == 1 {
+		return "1 problem"
+	}
+	return fmt.Sprintf("%d problems", len(e.Problems))
+}
+
+// add 记录一个问题
+func (e *SpecError) add(path, format string, args ...interface{}) {
+	e.Problems = append(e.Problems, SpecProblem{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// err 没有问题时返回 nil
+func (e *SpecError) err() error {
+	if len(e.Problems) == 0 {
+		return nil
+	}
+	return e
+}
+
+// HandlerRegistry 按命令路径注册的处理函数
+type HandlerRegistry struct {
+	handlers map[string]RunFunc
+}
+
+// NewHandlerRegistry 创建处理函数注册表
+func NewHandlerRegistry() *HandlerRegistry {
+	return &HandlerRegistry{handlers: make(map[string]RunFunc)}
+}
+
+// Handle 注册处理函数，path 为不含根命令名称的命令路径（如 "config init"），根命令为 ""
+func (r *HandlerRegistry) Handle(path string, fn RunFunc) *HandlerRegistry {
+	r.handlers[specPath(path)] = fn
+	return r
+}
+
+// lookup 查找处理函数
+func (r *HandlerRegistry) lookup(path string) RunFunc {
+	if r == nil {
+		return nil
+	}
+	return r.handlers[path]
+}
+
+// specPath 规范化命令路径
+func specPath(path string) string {
+	return strings.Join(strings.Fields(path), " ")
+}
+
+// childPath 拼接子命令路径
+func childPath(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + " " + name
+}
+
+// ParseSpec 解析 JSON 规格并校验其结构（不含处理函数），未知字段视为错误
+func ParseSpec(data []byte) (*CommandSpec, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	var spec CommandSpec
+	if err := decoder.Decode(&spec); err != nil {
+		return nil, fmt.Errorf("invalid command spec: %w", err)
+	}
+
+	serr := &SpecError{}
+	spec.validate("", nil, serr)
+	if err := serr.err(); err != nil {
+		return nil, err
+	}
+	return &spec, nil
+}
+
+// Validate 校验规格结构，并检查每个可执行节点都注册了处理函数、每个处理函数都对应规格中的命令
+//
+// 适合在测试中调用，保证规格文件与代码同步：
+//
+//	func TestSpec(t *testing.T) {
+//	    if err := spec.Validate(handlers); err != nil {
+//	        t.Fatal(err)
+//	    }
+//	}
+func (s *CommandSpec) Validate(handlers *HandlerRegistry) error {
+	serr := &SpecError{}
+	s.validate("", nil, serr)
+
+	paths := make(map[string]bool)
+	s.validateHandlers("", handlers, paths, serr)
+	if handlers != nil {
+		unknown := make([]string, 0)
+		for path := range handlers.handlers {
+			if !paths[path] {
+				unknown = append(unknown, path)
+			}
+		}
+		sort.Strings(unknown)
+		for _, path := range unknown {
+			serr.add(path, "handler registered for a command that is not in the spec")
+		}
+	}
+	return serr.err()
+}
+
+// IsRunnable 判断节点是否为可执行命令
+func (s *CommandSpec) IsRunnable() bool {
+	return s.Runnable || len(s.Commands) == 0
+}
+
+// validate 校验节点结构
+func (s *CommandSpec) validate(path string, parent *CommandSpec, serr *SpecError) {
+	if strings.TrimSpace(s.Name) == "" || strings.ContainsAny(s.Name, " \t\n") {
+		serr.add(path, "command name %q must be a single non-empty word", s.Name)
+	}
+	if s.Group != "" && (parent == nil || !parent.hasGroup(s.Group)) {
+		serr.add(path, "group %q is not declared on the parent command", s.Group)
+	}
+
+	groups := make(map[string]bool)
+	for _, g := range s.Groups {
+		if g.ID == "" || groups[g.ID] {
+			serr.add(path, "group id %q is empty or declared twice", g.ID)
+		}
+		groups[g.ID] = true
+	}
+
+	flags := make(map[string]bool)
+	shorthands := make(map[string]bool)
+	for _, f := range s.Flags {
+		switch {
+		case f.Name == "" || f.Name == "help" || isBuiltinFlag(f.Name):
+			serr.add(path, "flag name %q is empty or reserved", f.Name)
+		case flags[f.Name]:
+			serr.add(path, "flag --%s is declared twice", f.Name)
+		}
+		flags[f.Name] = true
+
+		if f.Shorthand != "" {
+			if len(f.Shorthand) != 1 || f.Shorthand == "h" || shorthands[f.Shorthand] {
+				serr.add(path, "flag --%s: shorthand %q must be a single unused letter", f.Name, f.Shorthand)
+			}
+			shorthands[f.Shorthand] = true
+		}
+		if _, err := f.defaultValue(); err != nil {
+			serr.add(path, "flag --%s: %v", f.Name, err)
+		}
+	}
+
+	names := make(map[string]bool)
+	for _, child := range s.Commands {
+		if child == nil {
+			serr.add(path, "empty command entry")
+			continue
+		}
+		for _, name := range append([]string{child.Name}, child.Aliases...) {
+			if names[name] {
+				serr.add(path, "subcommand name or alias %q is used twice", name)
+			}
+			names[name] = true
+		}
+		child.validate(childPath(path, child.Name), s, serr)
+	}
+}
+
+// validateHandlers 检查可执行节点的处理函数，并记录规格中的所有命令路径
+func (s *CommandSpec) validateHandlers(path string, handlers *HandlerRegistry, paths map[string]bool, serr *SpecError) {
+	paths[path] = true
+	if s.IsRunnable() && handlers.lookup(path) == nil {
+		serr.add(path, "no handler registered for runnable command")
+	}
+	for _, child := range s.Commands {
+		if child != nil {
+			child.validateHandlers(childPath(path, child.Name), handlers, paths, serr)
+		}
+	}
+}
+
+// hasGroup 判断是否声明了分组
+func (s *CommandSpec) hasGroup(id string) bool {
+	for _, g := range s.Groups {
+		if g.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// NewCommandFromSpec 根据规格构建命令树，并按命令路径绑定处理函数
+//
+// 规格与处理函数不匹配时返回 *SpecError，列出所有问题。opts 应用于根命令，
+// 可用于添加规格之外的设置（如 WithEnvPrefix、WithVersion）：
+//
+//	handlers := cobra.NewHandlerRegistry().
+//	    Handle("config init", runConfigInit).
+//	    Handle("config show", runConfigShow)
+//
+//	rootCmd, err := cobra.NewCommandFromSpec(spec, handlers, cobra.WithEnvPrefix("MYAPP"))
+func NewCommandFromSpec(spec *CommandSpec, handlers *HandlerRegistry, opts ...CommandOption) (*Command, error) {
+	if err := spec.Validate(handlers); err != nil {
+		return nil, err
+	}
+	return spec.build("", handlers, opts), nil
+}
+
+// build 构建节点对应的命令
+func (s *CommandSpec) build(path string, handlers *HandlerRegistry, extra []CommandOption) *Command {
+	opts := []CommandOption{
+		WithShort(s.Short),
+		WithLong(s.Long),
+		WithExample(s.Example),
+		WithAliases(s.Aliases...),
+		WithGroupID(s.Group),
+		WithDeprecated(s.Deprecated),
+	}
+	if s.Hidden {
+		opts = append(opts, WithHidden())
+	}
+	for _, g := range s.Groups {
+		opts = append(opts, WithGroup(g.ID, g.Title))
+	}
+	if len(s.Flags) > 0 {
+		opts = append(opts, s.flagOptions()...)
+	}
+	if fn := handlers.lookup(path); fn != nil {
+		opts = append(opts, WithRunE(fn))
+	}
+	for _, child := range s.Commands {
+		opts = append(opts, WithSubcommands(child.build(childPath(path, child.Name), handlers, nil)))
+	}
+
+	return NewCommand(s.Name, append(opts, extra...)...)
+}
+
+// flagOptions 生成定义 flags 的选项
+func (s *CommandSpec) flagOptions() []CommandOption {
+	var required []string
+	define := func(persistent bool) func(flags *FlagSet) {
+		return func(flags *FlagSet) {
+			for _, f := range s.Flags {
+				if f.Persistent == persistent {
+					f.define(flags)
+				}
+			}
+		}
+	}
+	for _, f := range s.Flags {
+		if f.Required {
+			required = append(required, f.Name)
+		}
+	}
+	return []CommandOption{
+		WithFlags(define(false)),
+		WithPersistentFlags(define(true)),
+		WithRequiredFlags(required...),
+	}
+}
+
+// defaultValue 按类型解析默认值，结构校验时已保证合法
+func (f FlagSpec) defaultValue() (interface{}, error) {
+	raw := f.Default
+	if len(raw) == 0 {
+		raw = nil
+	}
+
+	var err error
+	switch f.Type {
+	case "", "string":
+		var v string
+		err = unmarshalDefault(raw, &v)
+		return v, err
+	case "bool":
+		var v bool
+		err = unmarshalDefault(raw, &v)
+		return v, err
+	case "int", "count":
+		var v int
+		err = unmarshalDefault(raw, &v)
+		return v, err
+	case "int64":
+		var v int64
+		err = unmarshalDefault(raw, &v)
+		return v, err
+	case "float64":
+		var v float64
+		err = unmarshalDefault(raw, &v)
+		return v, err
+	case "duration":
+		var s string
+		if err = unmarshalDefault(raw, &s); err != nil || s == "" {
+			return time.Duration(0), err
+		}
+		return time.ParseDuration(s)
+	case "stringSlice":
+		var v []string
+		err = unmarshalDefault(raw, &v)
+		return v, err
+	default:
+		return nil, fmt.Errorf("unsupported type %q", f.Type)
+	}
+}
+
+// unmarshalDefault 解析默认值，未设置时保留零值
+func unmarshalDefault(raw json.RawMessage, v interface{}) error {
+	if raw == nil {
+		return nil
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return fmt.Errorf("invalid default %s: %w", raw, err)
+	}
+	return nil
+}
+
+// define 在 flag 集合上定义 flag
+func (f FlagSpec) define(flags *FlagSet) {
+	value, _ := f.defaultValue()
+	switch v := value.(type) {
+	case string:
+		flags.StringP(f.Name, f.Shorthand, v, f.Usage)
+	case bool:
+		flags.BoolP(f.Name, f.Shorthand, v, f.Usage)
+	case int:
+		if f.Type == "count" {
+			flags.CountP(f.Name, f.Shorthand, f.Usage)
+		} else {
+			flags.IntP(f.Name, f.Shorthand, v, f.Usage)
+		}
+	case int64:
+		flags.Int64P(f.Name, f.Shorthand, v, f.Usage)
+	case float64:
+		flags.Float64P(f.Name, f.Shorthand, v, f.Usage)
+	case time.Duration:
+		flags.DurationP(f.Name, f.Shorthand, v, f.Usage)
+	case []string:
+		flags.StringSliceP(f.Name, f.Shorthand, v, f.Usage)
+	}
+	if f.Hidden {
+		_ = flags.MarkHidden(f.Name)
+	}
+}
@@ -0,0 +1,127 @@
+package cobra
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testSpec = `{
+  "name": "spectest",
+  "groups": [{"id": "manage", "title": "Management Commands"}],
+  "commands": [
+    {
+      "name": "config",
+      "group": "manage",
+      "short": "Manage configuration",
+      "commands": [
+        {
+          "name": "init",
+          "short": "Initialize configuration",
+          "flags": [
+            {"name": "force", "shorthand": "f", "type": "bool"},
+            {"name": "timeout", "type": "duration", "default": "30s"}
+          ]
+        }
+      ]
+    }
+  ]
+}`
+
+func TestNewCommandFromSpec(t *testing.T) {
+	t.Setenv("COBRA_TREE", "")
+
+	spec, err := ParseSpec([]byte(testSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	handlers := NewHandlerRegistry().Handle("config  init", func(cmd *Command, args []string) error {
+		force, _ := cmd.Flags().GetBool("force")
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+		got = append(got, cmd.CommandPath(), timeout.String())
+		if force {
+			got = append(got, "force")
+		}
+		return nil
+	})
+	root, err := NewCommandFromSpec(spec, handlers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root.SetOut(&bytes.Buffer{})
+	root.SetErr(&bytes.Buffer{})
+	root.SetArgs([]string{"config", "init", "-f"})
+	if err := root.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{"spectest config init", "30s", "force"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("handler saw %q, want %q", got, want)
+	}
+	config, _, _ := root.Find([]string{"config"})
+	if config.GroupID != "manage" || config.Short != "Manage configuration" {
+		t.Errorf("config = {group %q, short %q}", config.GroupID, config.Short)
+	}
+}
+
+func TestSpecErrors(t *testing.T) {
+	runInit := func(cmd *Command, args []string) error { return nil }
+
+	tests := []struct {
+		name     string
+		spec     string
+		handlers *HandlerRegistry
+		want     []string // 错误信息中应包含的内容
+	}{
+		{
+			name: "unknown field",
+			spec: `{"name": "spectest", "shrot": "typo"}`,
+			want: []string{`unknown field "shrot"`},
+		},
+		{
+			name:     "missing handler",
+			spec:     testSpec,
+			handlers: NewHandlerRegistry(),
+			want:     []string{"(1 problem):", "config init: no handler registered for runnable command"},
+		},
+		{
+			name:     "handler for unknown path",
+			spec:     testSpec,
+			handlers: NewHandlerRegistry().Handle("config init", runInit).Handle("config show", runInit),
+			want:     []string{"(1 problem):", "config show: handler registered for a command that is not in the spec"},
+		},
+		{
+			name:     "multiple problems",
+			spec:     testSpec,
+			handlers: NewHandlerRegistry().Handle("deploy", runInit).Handle("config show", runInit),
+			want:     []string{"(3 problems):", "config init: no handler", "config show: handler registered", "deploy: handler registered"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec, err := ParseSpec([]byte(tt.spec))
+			if err == nil {
+				_, err = NewCommandFromSpec(spec, tt.handlers)
+			}
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if tt.handlers != nil {
+				var serr *SpecError
+				if !errors.As(err, &serr) {
+					t.Fatalf("error = %#v, want *SpecError", err)
+				}
+			}
+			for _, s := range tt.want {
+				if !strings.Contains(err.Error(), s) {
+					t.Errorf("error = %q, want it to contain %q", err, s)
+				}
+			}
+		})
+	}
+}
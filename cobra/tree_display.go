@@ -4,8 +4,8 @@ import (
 	"fmt"
 	"strings"
 
-	spf13cobra "github.com/spf13/cobra"
 	"github.com/charmbracelet/lipgloss"
+	spf13cobra "github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 )
 
@@ -15,6 +15,11 @@ type TreeConfig struct {
 	ShowFlags   bool
 	ShowLong    bool
 	IndentWidth int
+	Profile     string       // 当前生效的 profile，非空时显示在标题中
+	Sort        TreeSortMode // 子命令排序方式，为空时使用 TreeSortGroup
+	Format      TreeFormat   // 输出格式，为空时使用 TreeFormatText
+	Width       int          // 输出宽度，超出时换行；0 表示不换行
+	Catalog     *Catalog     // 界面文字的翻译，为 nil 时使用英文
 }
 
 // TreeTheme 树形展示主题
@@ -27,6 +32,8 @@ type TreeTheme struct {
 	FlagStyle            lipgloss.Style
 	FlagDescriptionStyle lipgloss.Style
 	LineStyle            lipgloss.Style
+	ErrorStyle           lipgloss.Style
+	GroupStyle           lipgloss.Style
 }
 
 // DefaultTreeTheme 返回默认树形主题
@@ -50,6 +57,13 @@ func DefaultTreeTheme() *TreeTheme {
 			Italic(true),
 		LineStyle: lipgloss.NewStyle().
 			Foreground(lipgloss.Color("245")), // dark gray
+		ErrorStyle: lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("203")), // red
+		GroupStyle: lipgloss.NewStyle().
+			Bold(true).
+			Underline(true).
+			Foreground(lipgloss.Color("213")), // pink
 	}
 }
 
@@ -74,6 +88,13 @@ func DraculaTreeTheme() *TreeTheme {
 			Italic(true),
 		LineStyle: lipgloss.NewStyle().
 			Foreground(lipgloss.Color("#44475A")), // current line
+		ErrorStyle: lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("#FF5555")), // red
+		GroupStyle: lipgloss.NewStyle().
+			Bold(true).
+			Underline(true).
+			Foreground(lipgloss.Color("#FF79C6")), // pink
 	}
 }
 
@@ -98,6 +119,13 @@ func NordTreeTheme() *TreeTheme {
 			Italic(true),
 		LineStyle: lipgloss.NewStyle().
 			Foreground(lipgloss.Color("#3B4252")), // polar night
+		ErrorStyle: lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("#BF616A")), // aurora red
+		GroupStyle: lipgloss.NewStyle().
+			Bold(true).
+			Underline(true).
+			Foreground(lipgloss.Color("#B48EAD")), // purple
 	}
 }
 
@@ -122,6 +150,13 @@ func MonokaiTreeTheme() *TreeTheme {
 			Italic(true),
 		LineStyle: lipgloss.NewStyle().
 			Foreground(lipgloss.Color("#3E3D32")), // line
+		ErrorStyle: lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("#F92672")), // pink
+		GroupStyle: lipgloss.NewStyle().
+			Bold(true).
+			Underline(true).
+			Foreground(lipgloss.Color("#AE81FF")), // purple
 	}
 }
 
@@ -146,6 +181,13 @@ func LightTreeTheme() *TreeTheme {
 			Italic(true),
 		LineStyle: lipgloss.NewStyle().
 			Foreground(lipgloss.Color("248")), // light gray
+		ErrorStyle: lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("160")), // red
+		GroupStyle: lipgloss.NewStyle().
+			Bold(true).
+			Underline(true).
+			Foreground(lipgloss.Color("90")), // purple
 	}
 }
 
@@ -166,26 +208,38 @@ func GetTreeThemeByName(name string) *TreeTheme {
 }
 
 // TreeDisplayNode 树形显示节点
+//
+// 节点可以序列化为 JSON（见 TreeSnapshot），渲染时不依赖原始命令。
 type TreeDisplayNode struct {
-	Name        string
-	Description string
-	Path        string
-	IsRunnable  bool
-	Children    []*TreeDisplayNode
-	Flags       []FlagDisplayInfo
-	Cmd         *Command // 保存对原始 Command 的引用
+	Name        string             `json:"name"`
+	Description string             `json:"description,omitempty"`
+	Path        string             `json:"path"`
+	IsRunnable  bool               `json:"runnable,omitempty"`
+	Group       string             `json:"group,omitempty"`  // 所属分组 ID
+	Groups      []TreeGroup        `json:"groups,omitempty"` // 为子命令声明的分组
+	Children    []*TreeDisplayNode `json:"children,omitempty"`
+	Flags       []FlagDisplayInfo  `json:"flags,omitempty"`
+	Cmd         *Command           `json:"-"` // 保存对原始 Command 的引用，从快照加载时为 nil
 }
 
 // FlagDisplayInfo flag 显示信息
 type FlagDisplayInfo struct {
-	Name         string
-	ShortName    string
-	Description  string
-	DefaultValue string
+	Name         string   `json:"name"`
+	ShortName    string   `json:"shorthand,omitempty"`
+	Description  string   `json:"description,omitempty"`
+	DefaultValue string   `json:"default,omitempty"`
+	EnvVars      []string `json:"env,omitempty"`
+	Type         string   `json:"type,omitempty"`
+	Persistent   bool     `json:"persistent,omitempty"` // 是否会被子命令继承
 }
 
 // DisplayTree 显示命令树（树形结构）
 func DisplayTree(root *Command, config *TreeConfig) string {
+	return RenderTree(root.displayNode(), config)
+}
+
+// RenderTree 渲染树形结构的命令树，tree 可以来自快照
+func RenderTree(tree *TreeDisplayNode, config *TreeConfig) string {
 	if config == nil {
 		config = &TreeConfig{
 			Theme:       DefaultTreeTheme(),
@@ -194,12 +248,15 @@ func DisplayTree(root *Command, config *TreeConfig) string {
 			IndentWidth: 4,
 		}
 	}
-
-	// 构建树形结构
-	tree := buildDisplayTree(root, "", 0)
+	config = withTreeDefaults(config)
+	tree = sortTree(tree, config.Sort)
 
 	// 生成显示文本
 	var builder strings.Builder
+	if config.Profile != "" {
+		builder.WriteString(renderProfileBadge(config))
+		builder.WriteString("\n")
+	}
 	renderTree(&builder, tree, "", config.Theme, true, 0, config)
 
 	return builder.String()
@@ -220,12 +277,15 @@ func buildDisplayTree(cmd *Command, path string, depth int) *TreeDisplayNode {
 		Description: cmd.Short,
 		Path:        currentPath,
 		IsRunnable:  cmd.Run != nil || cmd.RunE != nil,
+		Group:       cmd.GroupID,
+		Groups:      treeGroups(cmd.Command),
 		Children:    make([]*TreeDisplayNode, 0),
+		Flags:       collectFlagsForDisplay(cmd),
 		Cmd:         cmd, // 保存对原始 Command 的引用
 	}
 
-	// 递归处理子命令
-	for _, child := range cmd.Commands() {
+	// 递归处理子命令（按注册顺序保存，渲染时再按配置排序）
+	for _, child := range cmd.commandsInRegistrationOrder(cmd.Commands()) {
 		if !child.IsAvailableCommand() {
 			continue
 		}
@@ -233,8 +293,7 @@ func buildDisplayTree(cmd *Command, path string, depth int) *TreeDisplayNode {
 		if child.Name() == "completion" || child.Name() == "help" {
 			continue
 		}
-		wrapped := &Command{Command: child}
-		childNode := buildDisplayTree(wrapped, currentPath, depth+1)
+		childNode := buildDisplayTree(cmd.commandFor(child), currentPath, depth+1)
 		node.Children = append(node.Children, childNode)
 	}
 
@@ -272,12 +331,18 @@ func renderTree(builder *strings.Builder, node *TreeDisplayNode, prefix string,
 		} else {
 			descPrefix += "│   "
 		}
-		descLine := descPrefix + "└─ " + node.Description
-		builder.WriteString(theme.DescriptionStyle.Render(descLine))
-		builder.WriteString("\n")
+		for i, line := range wrapText(node.Description, wrapWidth(config.Width, displayWidth(descPrefix)+3)) {
+			marker := "└─ "
+			if i > 0 {
+				marker = "   "
+			}
+			builder.WriteString(theme.DescriptionStyle.Render(descPrefix + marker + line))
+			builder.WriteString("\n")
+		}
 	}
 
 	// 渲染子节点
+	headings := groupHeadings(node, config)
 	for i, child := range node.Children {
 		childIsLast := i == len(node.Children)-1
 		childPrefix := prefix
@@ -286,12 +351,23 @@ func renderTree(builder *strings.Builder, node *TreeDisplayNode, prefix string,
 		} else {
 			childPrefix += "│   "
 		}
+		// 分组标题
+		if headings[i] != "" {
+			builder.WriteString(theme.LineStyle.Render(childPrefix + "│ "))
+			builder.WriteString(theme.GroupStyle.Render(headings[i]))
+			builder.WriteString("\n")
+		}
 		renderTree(builder, child, childPrefix, theme, childIsLast, depth+1, config)
 	}
 }
 
 // DisplayFlatTree 显示扁平化的命令列表
 func DisplayFlatTree(root *Command, config *TreeConfig) string {
+	return RenderFlatTree(root.displayNode(), config)
+}
+
+// RenderFlatTree 渲染扁平化的命令列表，tree 可以来自快照
+func RenderFlatTree(tree *TreeDisplayNode, config *TreeConfig) string {
 	if config == nil {
 		config = &TreeConfig{
 			Theme:       DefaultTreeTheme(),
@@ -300,19 +376,40 @@ func DisplayFlatTree(root *Command, config *TreeConfig) string {
 			IndentWidth: 2,
 		}
 	}
+	config = withTreeDefaults(config)
 
 	// 获取所有命令路径
-	commands := getAllCommandPaths(root)
+	commands := getAllCommandPaths(sortTree(tree, config.Sort), config)
+	count := 0
+	for _, info := range commands {
+		if info.heading == "" {
+			count++
+		}
+	}
 
 	var builder strings.Builder
 
 	// 标题
-	title := fmt.Sprintf("Command Tree (%d commands)\n", len(commands))
+	title := fmt.Sprintf(config.Catalog.message(MsgTreeTitle), count)
 	builder.WriteString(config.Theme.RootStyle.Bold(true).Render(title))
-	builder.WriteString("\n")
+	if config.Profile != "" {
+		builder.WriteString(" ")
+		builder.WriteString(renderProfileBadge(config))
+	}
+	builder.WriteString("\n\n")
 
 	// 显示每个命令
-	for i, cmdInfo := range commands {
+	i := -1
+	for _, cmdInfo := range commands {
+		// 分组标题
+		if cmdInfo.heading != "" {
+			builder.WriteString("\n")
+			builder.WriteString(config.Theme.GroupStyle.Render(cmdInfo.heading))
+			builder.WriteString("\n")
+			continue
+		}
+		i++
+
 		// 命令路径
 		pathLine := fmt.Sprintf("%2d. %s", i+1, cmdInfo.path)
 		if cmdInfo.isRunnable {
@@ -321,37 +418,95 @@ func DisplayFlatTree(root *Command, config *TreeConfig) string {
 		builder.WriteString(config.Theme.LeafStyle.Render(pathLine))
 		builder.WriteString("\n")
 
-		// 描述
+		// 描述（与路径对齐，超出宽度时换行）
+		indent := len(fmt.Sprintf("%2d. ", i+1)) + 3
 		if cmdInfo.short != "" && config.ShowLong {
-			descPrefix := strings.Repeat(" ", len(fmt.Sprintf("%2d. ", i+1)))
-			descLine := descPrefix + "   " + cmdInfo.short
-			builder.WriteString(config.Theme.DescriptionStyle.Render(descLine))
-			builder.WriteString("\n")
+			for _, line := range wrapText(cmdInfo.short, wrapWidth(config.Width, indent)) {
+				builder.WriteString(config.Theme.DescriptionStyle.Render(strings.Repeat(" ", indent) + line))
+				builder.WriteString("\n")
+			}
 		}
 
 		// flags
-		if config.ShowFlags && cmdInfo.cmd != nil {
-			flags := collectFlagsForDisplay(cmdInfo.cmd)
-			for _, flag := range flags {
-				flagName := "--" + flag.Name
-				if flag.ShortName != "" {
-					flagName = "-" + flag.ShortName + ", " + flagName
-				}
-				flagPrefix := strings.Repeat(" ", len(fmt.Sprintf("%2d. ", i+1)))
-				flagLine := flagPrefix + "   " + flagName
-				builder.WriteString(config.Theme.FlagStyle.Render(flagLine))
-
-				// 如果有描述，在同一行显示
-				if flag.Description != "" {
-					builder.WriteString(" ")
-					builder.WriteString(config.Theme.FlagDescriptionStyle.Render(flag.Description))
+		if config.ShowFlags {
+			renderFlatFlags(&builder, cmdInfo.flags, indent, config)
+		}
+	}
+
+	return builder.String()
+}
+
+// renderFlatFlags 渲染命令的 flags：说明对齐到同一列，超出宽度时以悬挂缩进换行
+func renderFlatFlags(builder *strings.Builder, flags []FlagDisplayInfo, indent int, config *TreeConfig) {
+	// 有短名称的 flag 时，只有长名称的 flag 缩进对齐到长名称所在的列
+	shortIndent := ""
+	for _, flag := range flags {
+		if flag.ShortName != "" {
+			shortIndent = "    "
+			break
+		}
+	}
+
+	names := make([]string, len(flags))
+	nameWidth := 0
+	for i, flag := range flags {
+		names[i] = shortIndent + "--" + flag.Name
+		if flag.ShortName != "" {
+			names[i] = "-" + flag.ShortName + ", --" + flag.Name
+		}
+		nameWidth = max(nameWidth, displayWidth(names[i]))
+	}
+
+	column := indent + nameWidth + 2
+	width := wrapWidth(config.Width, column)
+	hanging := strings.Repeat(" ", column)
+	theme := config.Theme
+
+	for i, flag := range flags {
+		builder.WriteString(theme.FlagStyle.Render(strings.Repeat(" ", indent) + names[i]))
+
+		// 说明和环境变量各自换行，环境变量能放下时接在说明的最后一行
+		var lines []string
+		if flag.Description != "" {
+			for _, line := range wrapText(flag.Description, width) {
+				lines = append(lines, theme.FlagDescriptionStyle.Render(line))
+			}
+		}
+		if len(flag.EnvVars) > 0 {
+			env := "[env: " + strings.Join(flag.EnvVars, ", ") + "]"
+			last := len(lines) - 1
+			if last >= 0 && (width <= 0 || displayWidth(lines[last])+1+displayWidth(env) <= width) {
+				lines[last] += " " + theme.LineStyle.Render(env)
+			} else {
+				for _, line := range wrapText(env, width) {
+					lines = append(lines, theme.LineStyle.Render(line))
 				}
-				builder.WriteString("\n")
 			}
 		}
+
+		for j, line := range lines {
+			if j == 0 {
+				builder.WriteString(strings.Repeat(" ", column-indent-displayWidth(names[i])))
+			} else {
+				builder.WriteString("\n" + hanging)
+			}
+			builder.WriteString(line)
+		}
+		builder.WriteString("\n")
 	}
+}
 
-	return builder.String()
+// wrapWidth 从 column 列开始的文本可用的宽度，width 为 0 时不换行
+func wrapWidth(width, column int) int {
+	if width <= 0 {
+		return 0
+	}
+	return max(width-column, minWrapWidth)
+}
+
+// renderProfileBadge 渲染当前 profile 标识
+func renderProfileBadge(config *TreeConfig) string {
+	return config.Theme.BranchStyle.Render(fmt.Sprintf(config.Catalog.message(MsgTreeProfile), config.Profile))
 }
 
 // cmdInfo 命令信息
@@ -359,30 +514,38 @@ type cmdInfo struct {
 	path       string
 	short      string
 	isRunnable bool
-	cmd        *Command
+	flags      []FlagDisplayInfo
+	heading    string // 非空时表示分组标题而不是命令
 }
 
-// getAllCommandPaths 获取所有命令的路径
-func getAllCommandPaths(root *Command) []cmdInfo {
-	tree := buildDisplayTree(root, "", 0)
+// getAllCommandPaths 获取所有命令的路径，分组模式下在各分组前插入标题
+func getAllCommandPaths(tree *TreeDisplayNode, config *TreeConfig) []cmdInfo {
 	var infos []cmdInfo
-	collectPathsWithInfo(tree, "", &infos)
+	collectPathsWithInfo(tree, "", config, &infos)
 	return infos
 }
 
 // collectPathsWithInfo 收集所有路径和信息
-func collectPathsWithInfo(node *TreeDisplayNode, prefix string, infos *[]cmdInfo) {
+func collectPathsWithInfo(node *TreeDisplayNode, prefix string, config *TreeConfig, infos *[]cmdInfo) {
 	currentPath := prefix + node.Name
 	info := cmdInfo{
 		path:       currentPath,
 		short:      node.Description,
 		isRunnable: node.IsRunnable,
-		cmd:        node.Cmd, // 保存对原始 Command 的引用
+		flags:      node.Flags,
 	}
 	*infos = append(*infos, info)
 
-	for _, child := range node.Children {
-		collectPathsWithInfo(child, currentPath+" ", infos)
+	headings := groupHeadings(node, config)
+	for i, child := range node.Children {
+		if heading := headings[i]; heading != "" {
+			// 非根命令的分组标题带上所属命令的路径
+			if prefix != "" {
+				heading = currentPath + ": " + heading
+			}
+			*infos = append(*infos, cmdInfo{heading: heading})
+		}
+		collectPathsWithInfo(child, currentPath+" ", config, infos)
 	}
 }
 
@@ -393,7 +556,7 @@ func collectFlagsForDisplay(cmd *Command) []FlagDisplayInfo {
 
 	// 收集 LocalFlags
 	cmd.LocalFlags().VisitAll(func(flag *pflag.Flag) {
-		if strings.HasPrefix(flag.Name, "tree-") || flag.Name == "tree" || seen[flag.Name] {
+		if isBuiltinFlag(flag.Name) || seen[flag.Name] {
 			return
 		}
 
@@ -402,6 +565,9 @@ func collectFlagsForDisplay(cmd *Command) []FlagDisplayInfo {
 			ShortName:    flag.Shorthand,
 			Description:  flag.Usage,
 			DefaultValue: flag.DefValue,
+			EnvVars:      flag.Annotations[envAnnotation],
+			Type:         flag.Value.Type(),
+			Persistent:   cmd.PersistentFlags().Lookup(flag.Name) != nil,
 		}
 		flags = append(flags, info)
 		seen[flag.Name] = true
@@ -409,7 +575,7 @@ func collectFlagsForDisplay(cmd *Command) []FlagDisplayInfo {
 
 	// 收集 PersistentFlags
 	cmd.PersistentFlags().VisitAll(func(flag *pflag.Flag) {
-		if strings.HasPrefix(flag.Name, "tree-") || flag.Name == "tree" || seen[flag.Name] {
+		if isBuiltinFlag(flag.Name) || seen[flag.Name] {
 			return
 		}
 
@@ -418,6 +584,9 @@ func collectFlagsForDisplay(cmd *Command) []FlagDisplayInfo {
 			ShortName:    flag.Shorthand,
 			Description:  flag.Usage,
 			DefaultValue: flag.DefValue,
+			EnvVars:      flag.Annotations[envAnnotation],
+			Type:         flag.Value.Type(),
+			Persistent:   cmd.PersistentFlags().Lookup(flag.Name) != nil,
 		}
 		flags = append(flags, info)
 		seen[flag.Name] = true
@@ -448,7 +617,7 @@ func FindCommandByPathString(root *Command, path string) (*Command, error) {
 		}
 	}
 
-	return &Command{Command: current}, nil
+	return root.commandFor(current), nil
 }
 
 // GetCommandTreeString 获取命令树字符串（便捷函数）
@@ -0,0 +1,350 @@
+package cobra
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	spf13cobra "github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// WithRunTyped 设置强类型选项的命令执行函数
+//
+// T 必须是结构体（或结构体指针），字段通过标签与 flag 绑定：
+//
+//	type ServerOptions struct {
+//	    Port   int    `flag:"port,p" default:"8080" usage:"Server port" validate:"min=1,max=65535"`
+//	    Host   string `flag:"host,H" default:"0.0.0.0" usage:"Server host" validate:"required"`
+//	    Format string `flag:"format" env:"FORMAT" validate:"oneof=yaml json"`
+//	}
+//
+// 支持的标签：
+//   - flag: flag 名称与短名称（"name,n"），"-" 表示忽略该字段，缺省时使用字段名的 kebab-case 形式
+//   - default / usage: 自动注册 flag 时使用的默认值与说明
+//   - env: 命令行未设置该 flag 时读取的环境变量
+//   - validate: 校验规则（required, min=N, max=N, oneof=a b c, regex=EXPR），regex 必须放在最后
+//
+// 未在命令上定义的 flag 会根据字段类型自动注册；绑定与校验错误会聚合为一个 *ValidationError 返回。
+func WithRunTyped[T any](fn func(ctx context.Context, cmd *Command, opts T, args []string) error) CommandOption {
+	return func(c *Command) {
+		fields, err := parseOptionFields(reflect.TypeOf((*T)(nil)).Elem())
+		if err != nil {
+			panic(fmt.Sprintf("cobrax: WithRunTyped on %q: %v", c.Name(), err))
+		}
+
+		// 注册结构体中声明但命令上尚未定义的 flags
+		for _, field := range fields {
+			if err := field.define(c.Command.Flags()); err != nil {
+				panic(fmt.Sprintf("cobrax: WithRunTyped on %q: %v", c.Name(), err))
+			}
+		}
+
+		c.Command.RunE = func(cmd *spf13cobra.Command, args []string) error {
+			wrappedCmd := c.wrapCommand(cmd)
+
+			var opts T
+			if err := bindOptions(cmd.Flags(), fields, reflect.ValueOf(&opts).Elem()); err != nil {
+				return err
+			}
+
+			return fn(commandContext(cmd), wrappedCmd, opts, args)
+		}
+	}
+}
+
+// optionField 选项结构体字段描述
+type optionField struct {
+	index     []int
+	fieldName string
+	kind      reflect.Type
+	flagName  string
+	shorthand string
+	defValue  string
+	usage     string
+	env       string
+	rules     []validationRule
+}
+
+// parseOptionFields 解析选项结构体的字段标签
+func parseOptionFields(t reflect.Type) ([]*optionField, error) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("options type %s is not a struct", t)
+	}
+
+	var fields []*optionField
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		tag := sf.Tag.Get("flag")
+		if tag == "-" {
+			continue
+		}
+
+		// 嵌入的结构体展开处理
+		if sf.Anonymous && tag == "" && sf.Type.Kind() == reflect.Struct {
+			nested, err := parseOptionFields(sf.Type)
+			if err != nil {
+				return nil, err
+			}
+			for _, n := range nested {
+				n.index = append([]int{i}, n.index...)
+				fields = append(fields, n)
+			}
+			continue
+		}
+
+		if !isSupportedOptionType(sf.Type) {
+			return nil, fmt.Errorf("field %s has unsupported type %s", sf.Name, sf.Type)
+		}
+
+		field := &optionField{
+			index:     []int{i},
+			fieldName: sf.Name,
+			kind:      sf.Type,
+			defValue:  sf.Tag.Get("default"),
+			usage:     sf.Tag.Get("usage"),
+			env:       sf.Tag.Get("env"),
+		}
+
+		name, short, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = kebabCase(sf.Name)
+		}
+		field.flagName = name
+		field.shorthand = short
+
+		rules, err := parseValidationRules(sf.Tag.Get("validate"))
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", sf.Name, err)
+		}
+		field.rules = rules
+
+		fields = append(fields, field)
+	}
+
+	return fields, nil
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// isSupportedOptionType 判断字段类型是否支持绑定
+func isSupportedOptionType(t reflect.Type) bool {
+	if t == durationType {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Slice:
+		switch t.Elem().Kind() {
+		case reflect.String, reflect.Int:
+			return true
+		}
+	}
+	return false
+}
+
+// define 在 flag set 上注册该字段对应的 flag（已存在则跳过）
+func (f *optionField) define(fs *pflag.FlagSet) error {
+	if fs.Lookup(f.flagName) != nil {
+		return nil
+	}
+
+	// 先用零值注册，再通过 Set 写入默认值，统一各类型的默认值解析
+	switch {
+	case f.kind == durationType:
+		fs.DurationP(f.flagName, f.shorthand, 0, f.usage)
+	case f.kind.Kind() == reflect.String:
+		fs.StringP(f.flagName, f.shorthand, "", f.usage)
+	case f.kind.Kind() == reflect.Bool:
+		fs.BoolP(f.flagName, f.shorthand, false, f.usage)
+	case f.kind.Kind() >= reflect.Int && f.kind.Kind() <= reflect.Int64:
+		fs.Int64P(f.flagName, f.shorthand, 0, f.usage)
+	case f.kind.Kind() >= reflect.Uint && f.kind.Kind() <= reflect.Uint64:
+		fs.Uint64P(f.flagName, f.shorthand, 0, f.usage)
+	case f.kind.Kind() == reflect.Float32 || f.kind.Kind() == reflect.Float64:
+		fs.Float64P(f.flagName, f.shorthand, 0, f.usage)
+	case f.kind.Kind() == reflect.Slice && f.kind.Elem().Kind() == reflect.String:
+		fs.StringSliceP(f.flagName, f.shorthand, nil, f.usage)
+	case f.kind.Kind() == reflect.Slice && f.kind.Elem().Kind() == reflect.Int:
+		fs.IntSliceP(f.flagName, f.shorthand, nil, f.usage)
+	}
+
+	flag := fs.Lookup(f.flagName)
+	if f.defValue != "" {
+		if err := flag.Value.Set(f.defValue); err != nil {
+			return fmt.Errorf("invalid default %q for --%s: %w", f.defValue, f.flagName, err)
+		}
+	}
+	flag.DefValue = flag.Value.String()
+	return nil
+}
+
+// bindOptions 将 flag（及环境变量）的值绑定到结构体并执行校验
+func bindOptions(fs *pflag.FlagSet, fields []*optionField, target reflect.Value) error {
+	if target.Kind() == reflect.Ptr {
+		target.Set(reflect.New(target.Type().Elem()))
+		target = target.Elem()
+	}
+
+	verr := &ValidationError{}
+	for _, field := range fields {
+		raw, provided := field.lookup(fs)
+		value := target.FieldByIndex(field.index)
+
+		if provided {
+			if err := setFieldValue(value, raw); err != nil {
+				verr.add(field, "type", fmt.Sprintf("invalid value %q: %v", strings.Join(raw, ","), err))
+				continue
+			}
+		}
+
+		for _, rule := range field.rules {
+			if msg := rule.check(value); msg != "" {
+				verr.add(field, rule.name, msg)
+			}
+		}
+	}
+
+	if len(verr.Errors) > 0 {
+		return verr
+	}
+	return nil
+}
+
+// lookup 读取字段的原始值：优先命令行 flag，其次 env 标签，最后 flag 默认值
+func (f *optionField) lookup(fs *pflag.FlagSet) ([]string, bool) {
+	flag := fs.Lookup(f.flagName)
+
+	if flag != nil && flag.Changed {
+		return flagRawValue(flag), true
+	}
+
+	if f.env != "" {
+		if v, ok := os.LookupEnv(f.env); ok {
+			return []string{v}, true
+		}
+	}
+
+	if flag != nil {
+		return flagRawValue(flag), true
+	}
+
+	if f.defValue != "" {
+		return []string{f.defValue}, true
+	}
+	return nil, false
+}
+
+// flagRawValue 获取 flag 的字符串形式的值
+func flagRawValue(flag *pflag.Flag) []string {
+	if sv, ok := flag.Value.(pflag.SliceValue); ok {
+		return sv.GetSlice()
+	}
+	return []string{flag.Value.String()}
+}
+
+// setFieldValue 将字符串值转换后写入字段
+func setFieldValue(v reflect.Value, raw []string) error {
+	if v.Kind() == reflect.Slice {
+		var items []string
+		for _, r := range raw {
+			for _, item := range strings.Split(r, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					items = append(items, item)
+				}
+			}
+		}
+		slice := reflect.MakeSlice(v.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := setScalarValue(slice.Index(i), item); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+		return nil
+	}
+
+	s := ""
+	if len(raw) > 0 {
+		s = raw[0]
+	}
+	return setScalarValue(v, s)
+}
+
+// setScalarValue 将字符串值转换后写入标量字段
+func setScalarValue(v reflect.Value, s string) error {
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 0, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 0, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(n)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// kebabCase 将字段名转换为 kebab-case 形式（ListenAddr -> listen-addr）
+func kebabCase(name string) string {
+	var builder strings.Builder
+	runes := []rune(name)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			// 处理缩写词边界，如 HTTPPort -> http-port
+			if i > 0 && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				builder.WriteByte('-')
+			}
+			builder.WriteRune(unicode.ToLower(r))
+			continue
+		}
+		builder.WriteRune(r)
+	}
+	return builder.String()
+}
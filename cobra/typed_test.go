@@ -0,0 +1,159 @@
+package cobra
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type typedTestOptions struct {
+	Port    int           `flag:"port,p" default:"8080" usage:"Server port" validate:"min=1,max=65535"`
+	Host    string        `flag:"host" default:"0.0.0.0" validate:"required"`
+	Format  string        `flag:"format" env:"TYPEDTEST_FORMAT" default:"json" validate:"oneof=yaml json"`
+	Timeout time.Duration `env:"TYPEDTEST_TIMEOUT" default:"5s"`
+	Tags    []string      `flag:"tag"`
+}
+
+func TestWithRunTyped(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   []string
+		env    map[string]string
+		want   typedTestOptions
+		errors []string // 期望的校验错误（flag 名称），为空表示执行成功
+	}{
+		{
+			name: "defaults",
+			want: typedTestOptions{Port: 8080, Host: "0.0.0.0", Format: "json", Timeout: 5 * time.Second, Tags: []string{}},
+		},
+		{
+			name: "flags",
+			args: []string{"-p", "9090", "--host", "localhost", "--timeout", "1m", "--tag", "a,b", "--tag", "c"},
+			want: typedTestOptions{Port: 9090, Host: "localhost", Format: "json", Timeout: time.Minute, Tags: []string{"a", "b", "c"}},
+		},
+		{
+			name: "env",
+			env:  map[string]string{"TYPEDTEST_FORMAT": "yaml", "TYPEDTEST_TIMEOUT": "30s"},
+			want: typedTestOptions{Port: 8080, Host: "0.0.0.0", Format: "yaml", Timeout: 30 * time.Second, Tags: []string{}},
+		},
+		{
+			name: "flag beats env",
+			args: []string{"--format", "json"},
+			env:  map[string]string{"TYPEDTEST_FORMAT": "yaml"},
+			want: typedTestOptions{Port: 8080, Host: "0.0.0.0", Format: "json", Timeout: 5 * time.Second, Tags: []string{}},
+		},
+		{
+			name:   "invalid env value",
+			env:    map[string]string{"TYPEDTEST_TIMEOUT": "soon"},
+			errors: []string{"timeout"},
+		},
+		{
+			name:   "multiple rule violations",
+			args:   []string{"--port", "0", "--host", "", "--format", "xml"},
+			errors: []string{"port", "host", "format"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("COBRA_TREE", "")
+			for _, key := range []string{"TYPEDTEST_FORMAT", "TYPEDTEST_TIMEOUT"} {
+				t.Setenv(key, "") // 测试结束后恢复原值
+				os.Unsetenv(key)
+			}
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			var got typedTestOptions
+			ran := false
+			root := NewCommand("typedtest", WithRunTyped(func(ctx context.Context, cmd *Command, opts typedTestOptions, args []string) error {
+				got, ran = opts, true
+				return nil
+			}))
+			root.SetOut(&bytes.Buffer{})
+			root.SetErr(&bytes.Buffer{})
+			root.SetArgs(tt.args)
+			err := root.Execute()
+
+			if len(tt.errors) == 0 {
+				if err != nil {
+					t.Fatalf("Execute(%q) = %v", tt.args, err)
+				}
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("opts = %+v, want %+v", got, tt.want)
+				}
+				return
+			}
+
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("Execute(%q) = %v, want *ValidationError", tt.args, err)
+			}
+			if ran {
+				t.Error("run function called despite validation errors")
+			}
+			var flags []string
+			for _, fe := range verr.Errors {
+				flags = append(flags, fe.Flag)
+			}
+			if !reflect.DeepEqual(flags, tt.errors) {
+				t.Errorf("invalid flags = %q, want %q", flags, tt.errors)
+			}
+			if got := ExitCode(err); got != ExitCodeUsage {
+				t.Errorf("ExitCode() = %d, want %d", got, ExitCodeUsage)
+			}
+		})
+	}
+}
+
+func TestValidationErrorCount(t *testing.T) {
+	tests := []struct {
+		n    int
+		want string
+	}{
+		{1, "invalid options (1 error):"},
+		{3, "invalid options (3 errors):"},
+	}
+	for _, tt := range tests {
+		verr := &ValidationError{Errors: make([]FieldError, tt.n)}
+		if got := verr.Error(); !strings.HasPrefix(got, tt.want) {
+			t.Errorf("Error() = %q, want prefix %q", got, tt.want)
+		}
+	}
+}
+
+func TestParseOptionFieldsDefinesFlags(t *testing.T) {
+	cmd := NewCommand("typedtest", WithRunTyped(func(ctx context.Context, cmd *Command, opts typedTestOptions, args []string) error {
+		return nil
+	}))
+
+	tests := []struct {
+		name      string
+		shorthand string
+		defValue  string
+		usage     string
+	}{
+		{"port", "p", "8080", "Server port"},
+		{"host", "", "0.0.0.0", ""},
+		{"format", "", "json", ""},
+		{"timeout", "", "5s", ""},
+		{"tag", "", "[]", ""},
+	}
+	for _, tt := range tests {
+		flag := cmd.Flags().Lookup(tt.name)
+		if flag == nil {
+			t.Errorf("flag --%s not defined", tt.name)
+			continue
+		}
+		if flag.Shorthand != tt.shorthand || flag.DefValue != tt.defValue || flag.Usage != tt.usage {
+			t.Errorf("--%s = {%q %q %q}, want {%q %q %q}", tt.name,
+				flag.Shorthand, flag.DefValue, flag.Usage, tt.shorthand, tt.defValue, tt.usage)
+		}
+	}
+}
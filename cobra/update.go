@@ -0,0 +1,318 @@
+package cobra
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// updateCheckDisableEnv 设置为 true 时关闭更新检查
+const updateCheckDisableEnv = "COBRA_NO_UPDATE_CHECK"
+
+// updateFetchTimeout 获取发布清单的超时时间
+const updateFetchTimeout = 5 * time.Second
+
+// updateWaitTimeout 命令结束后等待后台获取完成的最长时间
+const updateWaitTimeout = time.Second
+
+// ReleaseManifest 发布清单
+//
+//	{"version": "v1.3.0", "url": "https://example.com/myapp/releases/v1.3.0", "notes": "..."}
+type ReleaseManifest struct {
+	Version string `json:"version"`
+	URL     string `json:"url,omitempty"`
+	Notes   string `json:"notes,omitempty"`
+}
+
+// updateSettings 更新检查设置
+type updateSettings struct {
+	manifest string        // 清单位置：本地路径、file:// 或 http(s):// URL
+	interval time.Duration // 两次检查的最小间隔
+}
+
+// updateCache 缓存的检查结果
+type updateCache struct {
+	CheckedAt time.Time       `json:"checkedAt"`
+	Latest    ReleaseManifest `json:"latest"`
+}
+
+// WithUpdateCheck 启用更新检查
+//
+// 命令执行期间在后台读取 manifest 指向的发布清单（本地文件路径或 URL），与当前版本按语义化版本比较，
+// 有新版本时在命令结束后输出提示；命令结束时获取尚未完成则最多再等待 1 秒。检查结果缓存在用户缓存目录下的 <root>/update-check.json，
+// 距上次检查不足 interval 时直接使用缓存（interval 为 0 时使用 24 小时）。
+// 当前版本取根命令的 Version（见 WithVersion），否则取主模块版本；无法解析为语义化版本时不检查。
+// 设置环境变量 COBRA_NO_UPDATE_CHECK=true 可关闭检查。
+func WithUpdateCheck(manifest string, interval time.Duration) CommandOption {
+	return func(c *Command) {
+		if interval <= 0 {
+			interval = 24 * time.Hour
+		}
+		c.update = &updateSettings{manifest: manifest, interval: interval}
+	}
+}
+
+// startUpdateCheck 开始更新检查，返回在命令结束后调用的提示函数
+//
+// 缓存过期时在后台获取清单，不阻塞命令执行；命令结束时最多再等待 updateWaitTimeout，
+// 仍未完成则使用旧的缓存结果，下次执行时重新获取。
+// 获取失败同样记录检查时间（保留旧的结果），避免每次执行都重新获取。
+func (c *Command) startUpdateCheck() func() {
+	if c.update == nil || strings.EqualFold(os.Getenv(updateCheckDisableEnv), "true") {
+		return func() {}
+	}
+
+	current := c.currentVersion()
+	if _, ok := parseSemver(current); !ok {
+		return func() {}
+	}
+
+	cachePath, err := c.updateCachePath()
+	if err != nil {
+		return func() {}
+	}
+	cache := readUpdateCache(cachePath)
+
+	var fetched chan *updateCache
+	if cache == nil || time.Since(cache.CheckedAt) >= c.update.interval {
+		fetched = make(chan *updateCache, 1)
+		go func() {
+			result := &updateCache{CheckedAt: time.Now()}
+			if latest, err := fetchManifest(c.update.manifest); err == nil {
+				result.Latest = *latest
+			} else if cache != nil {
+				result.Latest = cache.Latest
+			}
+			writeUpdateCache(cachePath, result)
+			fetched <- result
+		}()
+	}
+
+	return func() {
+		if fetched != nil {
+			timer := time.NewTimer(updateWaitTimeout)
+			defer timer.Stop()
+			select {
+			case cache = <-fetched:
+			case <-timer.C:
+			}
+		}
+		if cache != nil && compareSemver(cache.Latest.Version, current) > 0 {
+			c.PrintErrln()
+			c.PrintErrln(renderUpdateNotice(c.Name(), current, cache.Latest, c.getTreeConfig().Theme))
+		}
+	}
+}
+
+// currentVersion 返回当前运行的版本
+func (c *Command) currentVersion() string {
+	if c.Root().Version != "" {
+		return c.Root().Version
+	}
+	_, version := appModuleVersion()
+	return version
+}
+
+// updateCachePath 返回检查结果缓存文件路径
+func (c *Command) updateCachePath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, c.Root().Name(), "update-check.json"), nil
+}
+
+// readUpdateCache 读取缓存，不存在或无法解析时返回 nil
+func readUpdateCache(path string) *updateCache {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var cache updateCache
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return nil
+	}
+	return &cache
+}
+
+// writeUpdateCache 写入缓存，失败时忽略
+func writeUpdateCache(path string, cache *updateCache) {
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return
+	}
+	_ = os.WriteFile(path, data, 0o644)
+}
+
+// fetchManifest 读取发布清单
+func fetchManifest(location string) (*ReleaseManifest, error) {
+	data, err := readManifestSource(location)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest ReleaseManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid release manifest %s: %w", location, err)
+	}
+	if _, ok := parseSemver(manifest.Version); !ok {
+		return nil, fmt.Errorf("invalid version %q in release manifest %s", manifest.Version, location)
+	}
+	return &manifest, nil
+}
+
+// readManifestSource 从本地文件或 URL 读取清单内容
+func readManifestSource(location string) ([]byte, error) {
+	u, err := url.Parse(location)
+	if err != nil || u.Scheme == "" || len(u.Scheme) == 1 { // 单字母 scheme 视为 Windows 盘符
+		return os.ReadFile(location)
+	}
+
+	switch u.Scheme {
+	case "file":
+		return os.ReadFile(u.Path)
+	case "http", "https":
+		ctx, cancel := context.WithTimeout(context.Background(), updateFetchTimeout)
+		defer cancel()
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("fetch release manifest %s: %s", location, resp.Status)
+		}
+		return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	default:
+		return nil, fmt.Errorf("unsupported release manifest scheme %q", u.Scheme)
+	}
+}
+
+// renderUpdateNotice 使用主题渲染新版本提示
+func renderUpdateNotice(name, current string, latest ReleaseManifest, theme *TreeTheme) string {
+	var builder strings.Builder
+	builder.WriteString(theme.BranchStyle.Render(fmt.Sprintf("A new version of %s is available: ", name)))
+	builder.WriteString(theme.LineStyle.Render(current))
+	builder.WriteString(theme.BranchStyle.Render(" → "))
+	builder.WriteString(theme.LeafStyle.Render(latest.Version))
+	if latest.Notes != "" {
+		builder.WriteString("\n")
+		builder.WriteString(theme.DescriptionStyle.Render(latest.Notes))
+	}
+	if latest.URL != "" {
+		builder.WriteString("\n")
+		builder.WriteString(theme.FlagStyle.Render(latest.URL))
+	}
+	return builder.String()
+}
+
+// semver 语义化版本
+type semver struct {
+	major, minor, patch int
+	prerelease          []string
+}
+
+// parseSemver 解析语义化版本（允许 v 前缀，忽略 +build 元数据）
+func parseSemver(s string) (semver, bool) {
+	var v semver
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	s, _, _ = strings.Cut(s, "+")
+	s, pre, hasPre := strings.Cut(s, "-")
+
+	parts := strings.Split(s, ".")
+	if len(parts) != 3 {
+		return v, false
+	}
+	nums := make([]int, 3)
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return v, false
+		}
+		nums[i] = n
+	}
+	v.major, v.minor, v.patch = nums[0], nums[1], nums[2]
+
+	if hasPre {
+		if pre == "" {
+			return v, false
+		}
+		v.prerelease = strings.Split(pre, ".")
+	}
+	return v, true
+}
+
+// compareSemver 比较两个版本，a > b 返回 1，a < b 返回 -1，相等或无法解析返回 0
+func compareSemver(a, b string) int {
+	va, okA := parseSemver(a)
+	vb, okB := parseSemver(b)
+	if !okA || !okB {
+		return 0
+	}
+
+	for _, d := range []int{va.major - vb.major, va.minor - vb.minor, va.patch - vb.patch} {
+		if d != 0 {
+			return sign(d)
+		}
+	}
+
+	// 正式版本高于预发布版本
+	switch {
+	case len(va.prerelease) == 0 && len(vb.prerelease) == 0:
+		return 0
+	case len(va.prerelease) == 0:
+		return 1
+	case len(vb.prerelease) == 0:
+		return -1
+	}
+
+	for i := 0; i < len(va.prerelease) && i < len(vb.prerelease); i++ {
+		if d := comparePrerelease(va.prerelease[i], vb.prerelease[i]); d != 0 {
+			return d
+		}
+	}
+	return sign(len(va.prerelease) - len(vb.prerelease))
+}
+
+// comparePrerelease 比较预发布标识：数字按数值比较且低于字母标识，字母标识按字典序比较
+func comparePrerelease(a, b string) int {
+	na, errA := strconv.Atoi(a)
+	nb, errB := strconv.Atoi(b)
+	switch {
+	case errA == nil && errB == nil:
+		return sign(na - nb)
+	case errA == nil:
+		return -1
+	case errB == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// sign 返回整数的符号
+func sign(n int) int {
+	switch {
+	case n > 0:
+		return 1
+	case n < 0:
+		return -1
+	}
+	return 0
+}
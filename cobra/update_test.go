@@ -0,0 +1,52 @@
+package cobra
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestUpdateCheckShortCommand(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv(updateCheckDisableEnv, "")
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		time.Sleep(50 * time.Millisecond)
+		_, _ = w.Write([]byte(`{"version": "v1.3.0", "notes": "Faster startup"}`))
+	}))
+	defer server.Close()
+
+	run := func() string {
+		var stderr bytes.Buffer
+		root := NewCommand("updatetest",
+			WithVersion("v1.2.0"),
+			WithUpdateCheck(server.URL, time.Hour),
+			WithRun(func(cmd *Command, args []string) {}),
+		)
+		root.SetOut(&bytes.Buffer{})
+		root.SetErr(&stderr)
+		root.SetArgs([]string{})
+		if err := root.Execute(); err != nil {
+			t.Fatal(err)
+		}
+		return stderr.String()
+	}
+
+	// 命令立即结束时仍等待获取完成，输出提示并写入缓存
+	if out := run(); !strings.Contains(out, "v1.2.0 → v1.3.0") {
+		t.Fatalf("first run: missing update notice in %q", out)
+	}
+	// 缓存未过期时不再请求
+	if out := run(); !strings.Contains(out, "v1.3.0") {
+		t.Fatalf("second run: missing cached update notice in %q", out)
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("manifest fetched %d times, want 1", got)
+	}
+}
@@ -0,0 +1,200 @@
+package cobra
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ValidationError 选项校验错误（聚合所有字段的错误）
+type ValidationError struct {
+	Errors []FieldError
+}
+
+// FieldError 单个字段的校验错误
+type FieldError struct {
+	Field   string // 结构体字段名
+	Flag    string // 对应的 flag 名称
+	Rule    string // 未通过的规则（required, min, max, oneof, regex, type）
+	Message string // 错误说明
+}
+
+// Error 实现 error 接口
+func (e *ValidationError) Error() string {
+	lines := make([]string, 0, len(e.Errors)+1)
+	lines = append(lines, "invalid options ("+e.count()+"):")
+	for _, fe := range e.Errors {
+		lines = append(lines, fmt.Sprintf("  --%s: %s", fe.Flag, fe.Message))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// count 返回错误数量的描述，如 "1 error"、"2 errors"
+func (e *ValidationError) count() string {
+	if len(e.Errors) == 1 {
+		return "1 error"
+	}
+	return fmt.Sprintf("%d errors", len(e.Errors))
+}
+
+// Render 使用主题渲染校验错误
+func (e *ValidationError) Render(theme *TreeTheme) string {
+	if theme == nil {
+		theme = DefaultTreeTheme()
+	}
+
+	var builder strings.Builder
+	title := "✗ invalid options (" + e.count() + ")"
+	builder.WriteString(theme.ErrorStyle.Render(title))
+	for _, fe := range e.Errors {
+		builder.WriteString("\n")
+		builder.WriteString(theme.LineStyle.Render("  • "))
+		builder.WriteString(theme.FlagStyle.Render("--" + fe.Flag))
+		builder.WriteString(" ")
+		builder.WriteString(theme.FlagDescriptionStyle.Render(fe.Message))
+	}
+	return builder.String()
+}
+
+// add 添加一个字段错误
+func (e *ValidationError) add(field *optionField, rule, message string) {
+	e.Errors = append(e.Errors, FieldError{
+		Field:   field.fieldName,
+		Flag:    field.flagName,
+		Rule:    rule,
+		Message: message,
+	})
+}
+
+// validationRule 校验规则
+type validationRule struct {
+	name  string
+	param string
+	check func(v reflect.Value) string
+}
+
+// parseValidationRules 解析 validate 标签
+func parseValidationRules(tag string) ([]validationRule, error) {
+	var rules []validationRule
+	for tag != "" {
+		var part string
+		// regex 表达式中可能包含逗号，因此必须放在最后并吃掉剩余部分
+		if strings.HasPrefix(tag, "regex=") {
+			part, tag = tag, ""
+		} else {
+			part, tag, _ = strings.Cut(tag, ",")
+		}
+
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		name, param, _ := strings.Cut(part, "=")
+		rule, err := newValidationRule(name, param)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// newValidationRule 根据名称创建校验规则
+func newValidationRule(name, param string) (validationRule, error) {
+	rule := validationRule{name: name, param: param}
+
+	switch name {
+	case "required":
+		rule.check = func(v reflect.Value) string {
+			if v.IsZero() || (v.Kind() == reflect.Slice && v.Len() == 0) {
+				return "is required"
+			}
+			return ""
+		}
+
+	case "min", "max":
+		limit, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return rule, fmt.Errorf("invalid %s value %q", name, param)
+		}
+		rule.check = func(v reflect.Value) string {
+			n, isLen := numericValue(v)
+			if name == "min" && n < limit {
+				if isLen {
+					return fmt.Sprintf("must have length at least %s (got %s)", param, formatNumber(n))
+				}
+				return fmt.Sprintf("must be at least %s (got %s)", param, formatNumber(n))
+			}
+			if name == "max" && n > limit {
+				if isLen {
+					return fmt.Sprintf("must have length at most %s (got %s)", param, formatNumber(n))
+				}
+				return fmt.Sprintf("must be at most %s (got %s)", param, formatNumber(n))
+			}
+			return ""
+		}
+
+	case "oneof":
+		options := strings.Fields(param)
+		if len(options) == 0 {
+			return rule, fmt.Errorf("oneof requires at least one option")
+		}
+		rule.check = func(v reflect.Value) string {
+			// 空值交给 required 规则处理
+			if v.IsZero() {
+				return ""
+			}
+			s := fmt.Sprint(v.Interface())
+			for _, opt := range options {
+				if s == opt {
+					return ""
+				}
+			}
+			return fmt.Sprintf("must be one of %s (got %q)", strings.Join(options, ", "), s)
+		}
+
+	case "regex":
+		re, err := regexp.Compile(param)
+		if err != nil {
+			return rule, fmt.Errorf("invalid regex %q: %w", param, err)
+		}
+		rule.check = func(v reflect.Value) string {
+			if v.IsZero() {
+				return ""
+			}
+			s := fmt.Sprint(v.Interface())
+			if !re.MatchString(s) {
+				return fmt.Sprintf("must match %s (got %q)", param, s)
+			}
+			return ""
+		}
+
+	default:
+		return rule, fmt.Errorf("unknown validation rule %q", name)
+	}
+
+	return rule, nil
+}
+
+// numericValue 获取用于 min/max 比较的数值，字符串与切片使用长度
+func numericValue(v reflect.Value) (float64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), false
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), false
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), false
+	case reflect.String, reflect.Slice:
+		return float64(v.Len()), true
+	}
+	return 0, false
+}
+
+// formatNumber 格式化数值（整数不显示小数部分）
+func formatNumber(n float64) string {
+	return strconv.FormatFloat(n, 'f', -1, 64)
+}
@@ -1,6 +1,14 @@
 package cobra
 
+import (
+	"runtime/debug"
+)
+
 // Version information
+//
+// GitCommit, GitTreeState, CommitDate, BuildDate and GoVersion may be set with -ldflags -X.
+// GitCommit, GitTreeState, CommitDate and GoVersion are otherwise filled from the build information
+// embedded by the Go toolchain; BuildDate has no such source and stays empty unless set.
 var (
 	// Version is the current version of cobrax
 	Version = "v0.1.0"
@@ -8,9 +16,61 @@ var (
 	// GitCommit is the git commit hash
 	GitCommit = ""
 
+	// GitTreeState is "dirty" when the binary was built from a modified tree, "clean" otherwise
+	GitTreeState = ""
+
+	// CommitDate is the time of the commit the binary was built from
+	CommitDate = ""
+
 	// BuildDate is the date when the binary was built
 	BuildDate = ""
 
 	// GoVersion is the version of Go used to build the binary
 	GoVersion = ""
 )
+
+func init() {
+	fillBuildInfo()
+}
+
+// fillBuildInfo populates the empty version variables from runtime/debug.ReadBuildInfo
+func fillBuildInfo() {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return
+	}
+
+	if GoVersion == "" {
+		GoVersion = bi.GoVersion
+	}
+
+	for _, setting := range bi.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			if GitCommit == "" {
+				GitCommit = setting.Value
+			}
+		case "vcs.time":
+			if CommitDate == "" {
+				CommitDate = setting.Value
+			}
+		case "vcs.modified":
+			if GitTreeState == "" {
+				if setting.Value == "true" {
+					GitTreeState = "dirty"
+				} else {
+					GitTreeState = "clean"
+				}
+			}
+		}
+	}
+}
+
+// appModuleVersion returns the main module's path and version from the build information
+func appModuleVersion() (string, string) {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "", ""
+	}
+	return bi.Main.Path, bi.Main.Version
+}
@@ -0,0 +1,213 @@
+package cobra
+
+import (
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+
+	spf13cobra "github.com/spf13/cobra"
+)
+
+// versionSettings 版本命令设置
+type versionSettings struct {
+	version string // 应用版本，为空时使用主模块的版本
+}
+
+// BuildInfo 应用的版本与构建信息
+type BuildInfo struct {
+	App           string `json:"app"`
+	Version       string `json:"version"`
+	Module        string `json:"module,omitempty"`
+	GitCommit     string `json:"gitCommit,omitempty"`
+	GitTreeState  string `json:"gitTreeState,omitempty"`
+	CommitDate    string `json:"commitDate,omitempty"`
+	BuildDate     string `json:"buildDate,omitempty"`
+	GoVersion     string `json:"goVersion"`
+	Platform      string `json:"platform"`
+	CobraxVersion string `json:"cobraxVersion"`
+}
+
+// WithVersion 为根命令增加 version 子命令和 --version flag
+//
+// version 为应用自身的版本（通常通过 -ldflags 注入），为空时使用主模块的版本
+// （go install 安装的二进制为模块版本，本地构建为 "(devel)"）。
+// version 子命令支持 -o text|json 输出；同时启用 WithOutput 时改用全局的 --output（json、yaml 等）。
+func WithVersion(version string) CommandOption {
+	return func(c *Command) {
+		c.version = &versionSettings{version: version}
+		c.Command.Version = c.version.appVersion()
+	}
+}
+
+// appVersion 返回应用版本
+func (s *versionSettings) appVersion() string {
+	if s.version != "" {
+		return s.version
+	}
+	if _, version := appModuleVersion(); version != "" {
+		return version
+	}
+	return "(devel)"
+}
+
+// GetBuildInfo 获取应用的版本与构建信息
+func (c *Command) GetBuildInfo() BuildInfo {
+	info := BuildInfo{
+		App:           c.Root().Name(),
+		Version:       c.Root().Version,
+		GitCommit:     GitCommit,
+		GitTreeState:  GitTreeState,
+		CommitDate:    CommitDate,
+		BuildDate:     BuildDate,
+		GoVersion:     GoVersion,
+		Platform:      runtime.GOOS + "/" + runtime.GOARCH,
+		CobraxVersion: Version,
+	}
+	info.Module, _ = appModuleVersion()
+	if info.Version == "" {
+		info.Version = (&versionSettings{}).appVersion()
+	}
+	if info.GoVersion == "" {
+		info.GoVersion = runtime.Version()
+	}
+	return info
+}
+
+// renderBuildInfo 使用主题渲染版本信息
+func renderBuildInfo(info BuildInfo, theme *TreeTheme) string {
+	var builder strings.Builder
+	builder.WriteString(theme.RootStyle.Render(info.App))
+	builder.WriteString(" ")
+	builder.WriteString(theme.LeafStyle.Render(info.Version))
+
+	commit := info.GitCommit
+	if commit != "" && info.GitTreeState == "dirty" {
+		commit += " (modified)"
+	}
+
+	rows := [][2]string{
+		{"module", info.Module},
+		{"commit", commit},
+		{"committed", info.CommitDate},
+		{"built", info.BuildDate},
+		{"go", info.GoVersion},
+		{"platform", info.Platform},
+		{"cobrax", info.CobraxVersion},
+	}
+	for _, row := range rows {
+		if row[1] == "" {
+			continue
+		}
+		builder.WriteString("\n")
+		builder.WriteString(theme.LineStyle.Render(fmt.Sprintf("  %-11s", row[0]+":")))
+		builder.WriteString(theme.DescriptionStyle.Render(row[1]))
+	}
+	return builder.String()
+}
+
+// versionTemplateOnce 全局模板函数只注册一次
+var versionTemplateOnce sync.Once
+
+// versionFlagValue --version flag 的取值，记录所属的根命令，
+// 使全局的 cobraxVersion 模板函数能找到对应命令树的配置
+type versionFlagValue struct {
+	value bool
+	owner *Command
+}
+
+func (v *versionFlagValue) Set(s string) error {
+	value, err := strconv.ParseBool(s)
+	if err != nil {
+		return err
+	}
+	v.value = value
+	return nil
+}
+
+func (v *versionFlagValue) String() string { return strconv.FormatBool(v.value) }
+
+func (v *versionFlagValue) Type() string { return "bool" }
+
+// installVersionCommand 挂载 version 子命令并让 --version 使用相同的输出
+func (c *Command) installVersionCommand() {
+	versionTemplateOnce.Do(func() {
+		spf13cobra.AddTemplateFunc("cobraxVersion", func(cmd *spf13cobra.Command) string {
+			target := &Command{Command: cmd, treeConfig: &TreeConfig{Theme: DefaultTreeTheme()}}
+			if flag := cmd.Flags().Lookup("version"); flag != nil {
+				if value, ok := flag.Value.(*versionFlagValue); ok {
+					target = value.owner.commandFor(cmd)
+				}
+			}
+			return renderBuildInfo(target.GetBuildInfo(), target.getTreeConfig().Theme)
+		})
+	})
+	c.SetVersionTemplate("{{cobraxVersion .}}\n")
+
+	// 与 cobra 默认的 --version flag 相同（-v 未被占用时作为简写），取值中记录所属的根命令
+	if c.Flags().Lookup("version") == nil {
+		shorthand := ""
+		if c.Flags().ShorthandLookup("v") == nil && c.PersistentFlags().ShorthandLookup("v") == nil {
+			shorthand = "v"
+		}
+		flag := c.Flags().VarPF(&versionFlagValue{owner: c}, "version", shorthand, "version for "+c.DisplayName())
+		flag.NoOptDefVal = "true"
+	}
+
+	for _, child := range c.Commands() {
+		if child.Name() == "version" {
+			return
+		}
+	}
+
+	// localOutput version 是否使用自己的 -o text|json
+	localOutput := c.output == nil && c.PersistentFlags().Lookup(outputFlagName) == nil
+	versionCmd := &spf13cobra.Command{
+		Use:   "version",
+		Short: defaultMessages[MsgCmdVersion],
+		Args:  spf13cobra.NoArgs,
+		RunE: func(cmd *spf13cobra.Command, args []string) error {
+			target := c.commandFor(cmd)
+			info := c.GetBuildInfo()
+
+			// 启用了全局的 --output（见 WithOutput）时，显式指定了 text 以外的格式才按结构化格式输出
+			if c.output != nil {
+				if output, _ := cmd.Flags().GetString(outputFlagName); cmd.Flags().Changed(outputFlagName) && output != "text" {
+					return target.PrintResult(info)
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), renderBuildInfo(info, target.getTreeConfig().Theme))
+				return nil
+			}
+
+			output := "text"
+			if localOutput {
+				output, _ = cmd.Flags().GetString(outputFlagName)
+			}
+			switch output {
+			case "text":
+				fmt.Fprintln(cmd.OutOrStdout(), renderBuildInfo(info, target.getTreeConfig().Theme))
+			case "json":
+				data, err := json.MarshalIndent(info, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), string(data))
+			default:
+				return NewUsageError("unsupported output format %q", output).
+					WithHint("use one of: text, json")
+			}
+			return nil
+		},
+	}
+	// 没有全局的 --output 时使用 version 自己的 -o text|json（-o 未被占用时作为简写）
+	if localOutput {
+		shorthand := ""
+		if c.PersistentFlags().ShorthandLookup("o") == nil {
+			shorthand = "o"
+		}
+		versionCmd.Flags().StringP(outputFlagName, shorthand, "text", defaultMessages[MsgFlagVersionOutput])
+	}
+	c.Command.AddCommand(versionCmd)
+}
@@ -0,0 +1,52 @@
+package cobra
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestVersionOutput(t *testing.T) {
+	t.Setenv("COBRA_TREE", "")
+
+	tests := []struct {
+		name    string
+		opts    []CommandOption
+		args    []string
+		want    string
+		wantErr bool
+	}{
+		{"text", nil, []string{"version"}, "app v1.2.0", false},
+		{"local json", nil, []string{"version", "-o", "json"}, `"version": "v1.2.0"`, false},
+		{"local unsupported", nil, []string{"version", "-o", "yaml"}, "", true},
+		{"global yaml", []CommandOption{WithOutput(OutputTable)}, []string{"version", "-o", "yaml"}, "version: v1.2.0", false},
+		{"global text", []CommandOption{WithOutput(OutputTable)}, []string{"version", "-o", "text"}, "app v1.2.0", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := NewCommand("app", append([]CommandOption{WithVersion("v1.2.0")}, tt.opts...)...)
+			var stdout bytes.Buffer
+			root.SetOut(&stdout)
+			root.SetErr(&bytes.Buffer{})
+			root.SetArgs(tt.args)
+
+			err := root.Execute()
+			if tt.wantErr {
+				if ExitCode(err) != ExitCodeUsage {
+					t.Fatalf("Execute() = %v, want a usage error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(stdout.String(), tt.want) {
+				t.Errorf("output %q does not contain %q", stdout.String(), tt.want)
+			}
+			if strings.HasPrefix(tt.name, "local json") && !json.Valid(stdout.Bytes()) {
+				t.Errorf("invalid JSON: %s", stdout.String())
+			}
+		})
+	}
+}
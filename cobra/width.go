@@ -0,0 +1,152 @@
+package cobra
+
+import (
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/term"
+	"github.com/spf13/pflag"
+)
+
+// minWrapWidth 换行时每行文本的最小宽度，列过于靠右时不再继续压缩
+const minWrapWidth = 20
+
+// closingPunctuation 不能出现在行首的标点，换行时与前一个字符保持在同一行
+const closingPunctuation = "，。、；：！？）》」』】,.;:!?)]}"
+
+// treeWidth 确定树形输出的宽度：--tree-width > COLUMNS > 终端宽度，均无法确定时返回 0（不换行）
+func treeWidth(flags *pflag.FlagSet, out io.Writer) int {
+	if width, err := flags.GetInt("tree-width"); err == nil && width > 0 {
+		return width
+	}
+	if width, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && width > 0 {
+		return width
+	}
+	if file, ok := out.(*os.File); ok && term.IsTerminal(file.Fd()) {
+		if width, _, err := term.GetSize(file.Fd()); err == nil && width > 0 {
+			return width
+		}
+	}
+	return 0
+}
+
+// displayWidth 字符串在终端中的显示宽度（中日韩等宽字符占两列）
+func displayWidth(s string) int {
+	return lipgloss.Width(s)
+}
+
+// padRight 按显示宽度在右侧补齐空格
+func padRight(s string, width int) string {
+	if pad := width - displayWidth(s); pad > 0 {
+		return s + strings.Repeat(" ", pad)
+	}
+	return s
+}
+
+// wrapToken 换行的最小单位
+type wrapToken struct {
+	text  string
+	space bool // 与前一个单位之间是否有空格
+}
+
+// wrapText 按显示宽度将文本折成多行，width <= 0 时只按原有的换行符分行
+//
+// 英文按单词换行；中日韩等宽字符之间可以任意换行，但行首不会出现闭合标点。超过宽度的单词会被截断。
+func wrapText(text string, width int) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		if width <= 0 {
+			lines = append(lines, paragraph)
+			continue
+		}
+		lines = append(lines, wrapParagraph(paragraph, width)...)
+	}
+	return lines
+}
+
+// wrapParagraph 折行单个段落
+func wrapParagraph(text string, width int) []string {
+	var lines []string
+	var line strings.Builder
+	lineWidth := 0
+
+	for _, token := range tokenize(text) {
+		tokenWidth := displayWidth(token.text)
+		gap := 0
+		if token.space && lineWidth > 0 {
+			gap = 1
+		}
+
+		if lineWidth > 0 && lineWidth+gap+tokenWidth > width {
+			lines = append(lines, line.String())
+			line.Reset()
+			lineWidth, gap = 0, 0
+		}
+
+		// 单个单位超过宽度时截断
+		for tokenWidth > width {
+			head, rest := splitAtWidth(token.text, width)
+			lines = append(lines, head)
+			token.text, tokenWidth = rest, displayWidth(rest)
+		}
+
+		if gap > 0 {
+			line.WriteByte(' ')
+		}
+		line.WriteString(token.text)
+		lineWidth += gap + tokenWidth
+	}
+	if lineWidth > 0 || len(lines) == 0 {
+		lines = append(lines, line.String())
+	}
+	return lines
+}
+
+// tokenize 将文本拆分为换行单位：单词、单个宽字符，闭合标点附着在前一个单位上
+func tokenize(text string) []wrapToken {
+	var tokens []wrapToken
+	var word strings.Builder
+	space := false
+
+	flush := func() {
+		if word.Len() > 0 {
+			tokens = append(tokens, wrapToken{text: word.String(), space: space})
+			word.Reset()
+			space = false
+		}
+	}
+
+	for _, r := range text {
+		switch {
+		case r == ' ' || r == '\t':
+			flush()
+			space = true
+		case strings.ContainsRune(closingPunctuation, r) && word.Len() == 0 && !space && len(tokens) > 0:
+			tokens[len(tokens)-1].text += string(r)
+		case displayWidth(string(r)) > 1:
+			flush()
+			word.WriteRune(r)
+			flush()
+		default:
+			word.WriteRune(r)
+		}
+	}
+	flush()
+	return tokens
+}
+
+// splitAtWidth 在不超过 width 的位置截断字符串
+func splitAtWidth(s string, width int) (string, string) {
+	used := 0
+	for i, r := range s {
+		w := displayWidth(string(r))
+		if used+w > width && used > 0 {
+			return s[:i], s[i:]
+		}
+		used += w
+	}
+	return s, ""
+}
@@ -0,0 +1,37 @@
+package cobra
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWrapText(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		width int
+		want  []string
+	}{
+		{"no width", "a long line that is not wrapped", 0, []string{"a long line that is not wrapped"}},
+		{"words", "the quick brown fox jumps", 10, []string{"the quick", "brown fox", "jumps"}},
+		{"long word", "abcdefghijkl xy", 5, []string{"abcde", "fghij", "kl xy"}},
+		{"cjk", "监听地址格式为主机端口", 8, []string{"监听地址", "格式为主", "机端口"}},
+		{"cjk punctuation stays on the line", "留空时，使用默认端口。", 6, []string{"留空", "时，使", "用默认", "端口。"}},
+		{"mixed", "使用 host:port 格式", 10, []string{"使用", "host:port", "格式"}},
+		{"newlines", "first line\nsecond", 20, []string{"first line", "second"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := wrapText(tt.text, tt.width)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("wrapText(%q, %d) = %q, want %q", tt.text, tt.width, got, tt.want)
+			}
+			for _, line := range got {
+				if tt.width > 0 && displayWidth(line) > tt.width {
+					t.Errorf("line %q is wider than %d", line, tt.width)
+				}
+			}
+		})
+	}
+}
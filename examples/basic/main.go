@@ -1,95 +1,163 @@
 package main
 
 import (
+	"context"
+	_ "embed"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/ZHLX2005/cobrax/cobra"
 )
 
+// ServerOptions server 命令的选项
+type ServerOptions struct {
+	Port    int    `flag:"port,p" default:"8080" usage:"Server port" validate:"min=1,max=65535"`
+	Host    string `flag:"host,H" default:"0.0.0.0" usage:"Server host" validate:"required"`
+	TLS     bool   `flag:"tls,t" usage:"Enable TLS"`
+	Workers int    `flag:"workers,w" default:"4" usage:"Number of worker threads" validate:"min=1"`
+}
+
+// ClientOptions client 命令的选项
+type ClientOptions struct {
+	Server  string `flag:"server,s" default:"localhost:8080" usage:"Server address" validate:"required"`
+	Timeout int    `flag:"timeout,t" default:"30" usage:"Connection timeout in seconds" validate:"min=0"`
+}
+
+// zhCatalog 中文翻译（LANG=zh_CN.UTF-8 或 --lang zh 时使用）
+//
+//go:embed zh.json
+var zhCatalog []byte
+
+// ConfigEntry config show 输出的配置项
+type ConfigEntry struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+}
+
 func main() {
+	// 执行命令，按错误类型设置退出码
+	newRootCmd().ExecuteAndExit()
+}
+
+// releaseManifest 返回本地的发布清单：MYAPP_RELEASE_MANIFEST，默认为工作目录下的 release.json
+func releaseManifest() string {
+	if manifest := os.Getenv("MYAPP_RELEASE_MANIFEST"); manifest != "" {
+		return manifest
+	}
+	return "release.json"
+}
+
+// newRootCmd 构建完整的命令树（测试中每次执行都会重新构建）
+func newRootCmd() *cobra.Command {
+	zh, err := cobra.LoadCatalog(zhCatalog)
+	if err != nil {
+		panic(err)
+	}
+
 	// 创建根命令
 	rootCmd := cobra.NewCommand("myapp",
 		cobra.WithShort("My application"),
 		cobra.WithLong("My application is a demo application for cobra-x."),
 		cobra.WithTreeTheme(cobra.DefaultTreeTheme()),
+		cobra.WithEnvPrefix("MYAPP"),
+		cobra.WithConfigFile("myapp"),
+		cobra.WithProfiles(),
+		cobra.WithPanicRecovery(""),
+		cobra.WithVersion(""),
+		cobra.WithPlugins(),
+		cobra.WithTreeSnapshot(nil),
+		cobra.WithGroup("core", "Core Commands"),
+		cobra.WithGroup("manage", "Management Commands"),
+		cobra.WithUpdateCheck(releaseManifest(), 24*time.Hour),
+		cobra.WithLanguages(map[string]*cobra.Catalog{"zh": zh}),
+		cobra.WithOutput(cobra.OutputTable),
 	)
 
+	// 设置 MYAPP_TIMING=true 时输出每个命令的耗时
+	rootCmd.Use(timing)
+
 	// 添加 server 命令
 	serverCmd := cobra.NewCommand("server",
+		cobra.WithGroupID("core"),
 		cobra.WithShort("Start the server"),
 		cobra.WithLong("Start the server with the specified configuration."),
-		cobra.WithRun(func(cmd *cobra.Command, args []string) {
-			port, _ := cmd.Flags().GetString("port")
-			host, _ := cmd.Flags().GetString("host")
-			tls, _ := cmd.Flags().GetBool("tls")
-
-			fmt.Printf("Starting server on %s:%s\n", host, port)
-			if tls {
+		cobra.WithRunTyped(func(ctx context.Context, cmd *cobra.Command, opts ServerOptions, args []string) error {
+			fmt.Printf("Starting server on %s:%d\n", opts.Host, opts.Port)
+			if opts.TLS {
 				fmt.Println("TLS enabled")
 			}
+			return nil
 		}),
 	)
 
-	// 添加 server 命令的 flags
-	serverCmd.Command.Flags().StringP("port", "p", "8080", "Server port")
-	serverCmd.Command.Flags().StringP("host", "H", "0.0.0.0", "Server host")
-	serverCmd.Command.Flags().BoolP("tls", "t", false, "Enable TLS")
-	serverCmd.Command.Flags().IntP("workers", "w", 4, "Number of worker threads")
-
 	// 添加 client 命令
 	clientCmd := cobra.NewCommand("client",
+		cobra.WithGroupID("core"),
 		cobra.WithShort("Start the client"),
 		cobra.WithLong("Start the client with the specified configuration."),
-		cobra.WithRun(func(cmd *cobra.Command, args []string) {
-			server, _ := cmd.Flags().GetString("server")
-			timeout, _ := cmd.Flags().GetInt("timeout")
-
-			fmt.Printf("Connecting to server: %s\n", server)
-			fmt.Printf("Timeout: %d seconds\n", timeout)
+		cobra.WithRunTyped(func(ctx context.Context, cmd *cobra.Command, opts ClientOptions, args []string) error {
+			fmt.Printf("Connecting to server: %s\n", opts.Server)
+			fmt.Printf("Timeout: %d seconds\n", opts.Timeout)
+			return nil
 		}),
 	)
 
-	// 添加 client 命令的 flags
-	clientCmd.Command.Flags().StringP("server", "s", "localhost:8080", "Server address")
-	clientCmd.Command.Flags().IntP("timeout", "t", 30, "Connection timeout in seconds")
-
-	// 添加 config 命令
+	// 添加 config 命令（子命令和 flags 均通过选项声明）
 	configCmd := cobra.NewCommand("config",
+		cobra.WithGroupID("manage"),
+		cobra.WithAliases("cfg"),
 		cobra.WithShort("Manage configuration"),
 		cobra.WithLong("Manage application configuration."),
+		cobra.WithSubcommands(
+			cobra.NewCommand("init",
+				cobra.WithShort("Initialize configuration"),
+				cobra.WithArgs(cobra.NoArgs),
+				cobra.WithFlags(func(flags *cobra.FlagSet) {
+					flags.BoolP("force", "f", false, "Force overwrite existing config")
+				}),
+				cobra.WithRun(func(cmd *cobra.Command, args []string) {
+					force, _ := cmd.Flags().GetBool("force")
+					fmt.Println("Initializing configuration...")
+					if force {
+						fmt.Println("Force mode enabled")
+					}
+				}),
+			),
+			cobra.NewCommand("show",
+				cobra.WithShort("Show configuration"),
+				cobra.WithExample("  myapp config show -o yaml\n  myapp config show --columns key,value --sort-by key"),
+				cobra.WithArgs(cobra.NoArgs),
+				cobra.WithRunE(func(cmd *cobra.Command, args []string) error {
+					// 输出格式由根命令的 --output 等 flags 决定
+					return cmd.PrintResult([]ConfigEntry{
+						{Key: "server.port", Value: "8080", Source: "default"},
+						{Key: "server.host", Value: "0.0.0.0", Source: "default"},
+						{Key: "client.timeout", Value: "30", Source: "default"},
+					})
+				}),
+			),
+		),
 	)
 
-	// config init 子命令
-	configInitCmd := cobra.NewCommand("init",
-		cobra.WithShort("Initialize configuration"),
-		cobra.WithRun(func(cmd *cobra.Command, args []string) {
-			force, _ := cmd.Flags().GetBool("force")
-			fmt.Println("Initializing configuration...")
-			if force {
-				fmt.Println("Force mode enabled")
-			}
-		}),
-	)
-	configInitCmd.Command.Flags().BoolP("force", "f", false, "Force overwrite existing config")
-
-	// config show 子命令
-	configShowCmd := cobra.NewCommand("show",
-		cobra.WithShort("Show configuration"),
-		cobra.WithRun(func(cmd *cobra.Command, args []string) {
-			format, _ := cmd.Flags().GetString("format")
-			fmt.Printf("Showing configuration in %s format\n", format)
-		}),
-	)
-	configShowCmd.Command.Flags().StringP("format", "f", "yaml", "Output format (yaml, json)")
-
-	configCmd.AddCommand(configInitCmd, configShowCmd)
-
 	// 添加所有命令到根命令
 	rootCmd.AddCommand(serverCmd, clientCmd, configCmd)
 
-	// 执行命令
-	if err := rootCmd.Execute(); err != nil {
-		os.Exit(1)
+	return rootCmd
+}
+
+// timing 输出命令耗时的中间件
+func timing(next cobra.RunFunc) cobra.RunFunc {
+	return func(cmd *cobra.Command, args []string) error {
+		if os.Getenv("MYAPP_TIMING") != "true" {
+			return next(cmd, args)
+		}
+
+		start := time.Now()
+		defer func() {
+			cmd.PrintErrf("%s took %s\n", cmd.CommandPath(), time.Since(start))
+		}()
+		return next(cmd, args)
 	}
 }
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+
+	"github.com/ZHLX2005/cobrax/cobra"
+	"github.com/ZHLX2005/cobrax/cobra/cobraxtest"
+)
+
+// themeNames 所有内置主题
+var themeNames = []string{"default", "dracula", "nord", "monokai", "light"}
+
+// renderers 被测试的渲染函数
+var renderers = map[string]func(*cobra.Command, *cobra.TreeConfig) string{
+	"flat": cobra.DisplayFlatTree,
+	"tree": cobra.DisplayTree,
+}
+
+// ansiPattern 匹配 ANSI 转义序列
+var ansiPattern = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// TestThemeGolden 对每个主题和渲染方式比较带颜色（固定为 TrueColor）和无颜色的输出
+//
+// 去掉颜色后的输出必须与无颜色的输出一致，保证样式不影响连接符、缩进和 flag 布局。
+// 使用 COBRAX_UPDATE_GOLDEN=true go test 重新生成 testdata 中的文件。
+func TestThemeGolden(t *testing.T) {
+	// 快照缓存写入临时目录
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("MYAPP_TIMING", "")
+
+	for _, theme := range themeNames {
+		for _, mode := range []string{"flat", "tree"} {
+			name := mode + "-" + theme
+			t.Run(name, func(t *testing.T) {
+				colored := renderWithProfile(t, termenv.TrueColor, mode, theme)
+				plain := renderWithProfile(t, termenv.Ascii, mode, theme)
+
+				if stripped := ansiPattern.ReplaceAllString(colored, ""); stripped != plain {
+					t.Errorf("colored output differs from plain output after stripping colors:\n%s\n---\n%s", stripped, plain)
+				}
+				if colored == plain {
+					t.Errorf("colored output contains no color")
+				}
+
+				cobraxtest.AssertGolden(t, name+"-color", colored)
+				cobraxtest.AssertGolden(t, name, plain)
+			})
+		}
+	}
+}
+
+// renderWithProfile 使用指定的颜色配置渲染示例 CLI 的命令树
+func renderWithProfile(t *testing.T, profile termenv.Profile, mode, theme string) string {
+	t.Helper()
+
+	lipgloss.SetColorProfile(profile)
+	lipgloss.SetHasDarkBackground(true)
+	t.Cleanup(func() { lipgloss.SetColorProfile(termenv.Ascii) })
+
+	config := &cobra.TreeConfig{
+		Theme:     cobra.GetTreeThemeByName(theme),
+		ShowFlags: true,
+		ShowLong:  true,
+	}
+	return renderers[mode](newRootCmd(), config)
+}
+
+// TestWrapGolden 比较按宽度换行后的输出
+func TestWrapGolden(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	lipgloss.SetColorProfile(termenv.Ascii)
+
+	for mode, width := range map[string]int{"flat": 60, "tree": 30} {
+		t.Run(mode, func(t *testing.T) {
+			config := &cobra.TreeConfig{ShowFlags: true, ShowLong: true, Width: width}
+			cobraxtest.AssertGolden(t, fmt.Sprintf("%s-width-%d", mode, width), renderers[mode](newRootCmd(), config))
+		})
+	}
+}
+
+// TestLocaleGolden 比较中文环境下的命令树和帮助信息
+func TestLocaleGolden(t *testing.T) {
+	tests := map[string][]string{
+		"zh-tree":        {"--lang", "zh", "--tree"},
+		"zh-help-server": {"server", "--help"},
+	}
+	runner := cobraxtest.Runner{Env: map[string]string{"LANG": "zh_CN.UTF-8"}}
+	for name, args := range tests {
+		t.Run(name, func(t *testing.T) {
+			result := runner.Run(newRootCmd(), args...)
+			if result.Err != nil {
+				t.Fatalf("run %v: %v\n%s", args, result.Err, result.Stderr)
+			}
+			cobraxtest.AssertGolden(t, name, result.Stdout)
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ZHLX2005/cobrax/cobra"
+)
+
+// cli.json 定义命令结构和帮助文本，修改文案无需改动代码
+//
+//go:embed cli.json
+var cliSpec []byte
+
+func main() {
+	spec, err := cobra.ParseSpec(cliSpec)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	handlers := cobra.NewHandlerRegistry().
+		Handle("add", func(cmd *cobra.Command, args []string) error {
+			tags, _ := cmd.Flags().GetStringSlice("tag")
+			fmt.Printf("Added %q (tags: %s)\n", strings.Join(args, " "), strings.Join(tags, ", "))
+			return nil
+		}).
+		Handle("list", func(cmd *cobra.Command, args []string) error {
+			limit, _ := cmd.Flags().GetInt("limit")
+			file, _ := cmd.Flags().GetString("file")
+			fmt.Printf("Listing up to %d notes from %s\n", limit, file)
+			return nil
+		}).
+		Handle("config init", func(cmd *cobra.Command, args []string) error {
+			force, _ := cmd.Flags().GetBool("force")
+			fmt.Printf("Initializing configuration (force: %t)\n", force)
+			return nil
+		})
+
+	rootCmd, err := cobra.NewCommandFromSpec(spec, handlers, cobra.WithEnvPrefix("NOTES"))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	rootCmd.ExecuteAndExit()
+}